@@ -20,12 +20,18 @@ package accumulation
 import (
 	"errors"
 	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
 	"reflect"
 	"runtime/debug"
 
 	"go.uber.org/nilaway/annotation"
 	"go.uber.org/nilaway/assertion"
+	"go.uber.org/nilaway/assertion/anonymousfunc"
+	"go.uber.org/nilaway/assertion/function"
 	"go.uber.org/nilaway/assertion/function/assertiontree"
+	"go.uber.org/nilaway/assertion/function/nonnilresults"
 	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/diagnostic"
 	"go.uber.org/nilaway/inference"
@@ -45,8 +51,33 @@ var Analyzer = &analysis.Analyzer{
 	Doc:        _doc,
 	Run:        run,
 	FactTypes:  []analysis.Fact{new(inference.InferredMap)},
-	Requires:   []*analysis.Analyzer{config.Analyzer, assertion.Analyzer, annotation.Analyzer},
-	ResultType: reflect.TypeOf(([]analysis.Diagnostic)(nil)),
+	Requires:   []*analysis.Analyzer{config.Analyzer, assertion.Analyzer, annotation.Analyzer, function.Analyzer, anonymousfunc.Analyzer, nonnilresults.Analyzer},
+	ResultType: reflect.TypeOf((*Result)(nil)),
+}
+
+// Result is the result of the accumulation analyzer.
+type Result struct {
+	// Diagnostics are the diagnostics for the potential nil panics in the package.
+	Diagnostics []analysis.Diagnostic
+	// Annotations are the diagnostics for the inferred annotations of the exported API of the
+	// package, each carrying a suggested fix that writes the annotations back into the source (see
+	// annotate). They are only available in the inference mode, and only computed if
+	// config.Config.Annotate or config.Config.CollectAnnotations is set.
+	Annotations []analysis.Diagnostic
+	// Stats are the statistics of the analysis of the package.
+	Stats Stats
+}
+
+// Stats are the statistics of the analysis of a package.
+type Stats struct {
+	// Triggers is the number of full triggers collected from the package.
+	Triggers int
+	// NilableSites, NonnilSites, and UndeterminedSites are the numbers of the annotation sites of
+	// the package that are determined nilable, determined nonnil, and left undetermined (i.e., to
+	// be determined by downstream packages) after the analysis of the package, respectively.
+	NilableSites, NonnilSites, UndeterminedSites int
+	// Functions are the statistics of the analyses of the functions in the package.
+	Functions function.Stats
 }
 
 // run is the primary driver function for NilAway's analysis.
@@ -83,28 +114,29 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 			// return value `result` in-place.
 			// Diagnostics with invalid positions (<= 0) will be silently suppressed, so here we use 1.
 			d := analysis.Diagnostic{Pos: 1, Message: fmt.Sprintf("INTERNAL PANIC: %s\n%s", r, string(debug.Stack()))}
-			if diagnostics, ok := result.([]analysis.Diagnostic); ok {
-				result = append(diagnostics, d)
+			if res, ok := result.(*Result); ok && res != nil {
+				res.Diagnostics = append(res.Diagnostics, d)
 			} else {
-				result = []analysis.Diagnostic{d}
+				result = &Result{Diagnostics: []analysis.Diagnostic{d}}
 			}
 		}
 	}()
 
 	conf := pass.ResultOf[config.Analyzer].(*config.Config)
 	if !conf.IsPkgInScope(pass.Pkg) {
-		// Must return a typed nil since the driver is using reflection to retrieve the result.
-		return ([]analysis.Diagnostic)(nil), nil
+		return &Result{}, nil
 	}
 
 	assertionsResult := pass.ResultOf[assertion.Analyzer].(*analysishelper.Result[[]annotation.FullTrigger])
 	annotationsResult := pass.ResultOf[annotation.Analyzer].(*analysishelper.Result[*annotation.ObservedMap])
-	if err := errors.Join(annotationsResult.Err, assertionsResult.Err); err != nil {
+	funcLitResult := pass.ResultOf[anonymousfunc.Analyzer].(*analysishelper.Result[map[*ast.FuncLit]*anonymousfunc.FuncLitInfo])
+	nonNilResultsResult := pass.ResultOf[nonnilresults.Analyzer].(*analysishelper.Result[nonnilresults.Map])
+	if err := errors.Join(annotationsResult.Err, assertionsResult.Err, funcLitResult.Err, nonNilResultsResult.Err); err != nil {
 		// For now, if there are any errors in the sub-analyzers, we directly emit diagnostics on the
 		// errors. However, in the future we could implement error recovery and make use of the partial
 		// information to continue the analysis.
 		// Diagnostics with invalid positions (<= 0) will be silently suppressed, so here we use 1.
-		return []analysis.Diagnostic{{Pos: 1, Message: fmt.Sprintf("INTERNAL ERROR(s):\n%s", err)}}, nil
+		return &Result{Diagnostics: []analysis.Diagnostic{{Pos: 1, Message: fmt.Sprintf("INTERNAL ERROR(s):\n%s", err)}}}, nil
 	}
 
 	diagnosticEngine := diagnostic.NewEngine(pass)
@@ -112,19 +144,37 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 	// Create an inference engine and observe (load) information from upstream dependencies (i.e.,
 	// mappings between annotation sites and their inferred values).
 	inferenceEngine := inference.NewEngine(pass, diagnosticEngine)
-	inferenceEngine.ObserveUpstream()
+	// Incompatible upstream facts are ignored (i.e., treated as unknown) by the engine, so here we
+	// simply keep the error and report it along with the other diagnostics.
+	upstreamErr := inferenceEngine.ObserveUpstream()
 
 	// Determine inference type based on comments in package doc string.
 	mode := inference.DetermineMode(pass)
 
+	// The annotations of the anonymous functions are stored for their fake function declarations,
+	// which are only known here (see anonymousfunc.FuncLitInfo).
+	fakeFuncObjs := make(map[*ast.FuncLit]*types.Func, len(funcLitResult.Res))
+	for funcLit, info := range funcLitResult.Res {
+		fakeFuncObjs[funcLit] = info.FakeFuncObj
+	}
+	annotationsResult.Res.AddFuncLits(fakeFuncObjs)
+	// The results annotated to escape from the guarding (e.g., `always-nonnil(result 0)`) must be
+	// nonnil regardless of the guarding results.
+	annotationsResult.Res.AddNonNilResults(nonNilResultsResult.Res.IsUnguarded)
+
 	// First observe all annotations from annotationsResult (observes only syntactic annotations
 	// for FullInfer mode, otherwise all annotations for NoInfer)
 	inferenceEngine.ObserveAnnotations(annotationsResult.Res, mode)
 
-	var (
-		inferredMap *inference.InferredMap
-		diagnostics []analysis.Diagnostic
-	)
+	res := &Result{Stats: Stats{Triggers: len(assertionsResult.Res)}}
+	// The function analyzer has no errors here, since they would have been propagated by the
+	// assertion analyzer above.
+	var cancelled []token.Pos
+	if functionResult := pass.ResultOf[function.Analyzer].(*analysishelper.Result[*function.Result]); functionResult.Res != nil {
+		res.Stats.Functions = functionResult.Res.Stats
+		cancelled = functionResult.Res.Cancelled
+	}
+	var inferredMap *inference.InferredMap
 	switch mode {
 	case inference.FullInfer:
 		// Incorporate assertions from this package one-by-one into the inferredAnnotationMap, possibly
@@ -132,7 +182,12 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		// sites unless we really have a reason they have to be determined.
 		inferenceEngine.ObservePackage(assertionsResult.Res)
 		inferredMap = inferenceEngine.InferredMap()
-		diagnostics = diagnosticEngine.Diagnostics(conf.GroupErrorMessages)
+		res.Diagnostics = diagnosticEngine.Diagnostics(conf.GroupErrorMessages)
+		// Computing the annotations takes another pass over the exported API, so we only do it if
+		// they are requested.
+		if conf.Annotate || conf.CollectAnnotations {
+			res.Annotations = annotate(pass, conf, inferredMap)
+		}
 
 	case inference.NoInfer:
 		// In non-inference case - use the classical assertionNode.CheckErrors method to determine error outputs
@@ -140,7 +195,7 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		checkErrors(assertionsResult.Res, inferredMap, diagnosticEngine)
 		// Retrieve the diagnostics from the engine. Note that we should not group the
 		// diagnostics for easier unit testing.
-		diagnostics = diagnosticEngine.Diagnostics(false /* grouping */)
+		res.Diagnostics = diagnosticEngine.Diagnostics(false /* grouping */)
 
 	default:
 		panic("Invalid mode for running NilAway")
@@ -157,7 +212,22 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 	// [gob encoding]: https://pkg.go.dev/encoding/gob#hdr-Basics
 	inferredMap.Export(pass)
 
-	return diagnostics, nil
+	res.Stats.NilableSites, res.Stats.NonnilSites, res.Stats.UndeterminedSites = inferredMap.CountSites(pass.Pkg.Path())
+
+	if len(cancelled) > 0 {
+		// The functions whose analyses were cancelled have no errors reported, so we make the gap
+		// visible at the first of them.
+		res.Diagnostics = append(res.Diagnostics, analysis.Diagnostic{Pos: cancelled[0], Message: fmt.Sprintf(
+			"INCOMPLETE ANALYSIS: the analyses of %d function(s) were cancelled for exceeding the memory budget (see -%s), so no errors are reported for them",
+			len(cancelled), config.MemoryBudgetFlag)})
+	}
+
+	if upstreamErr != nil {
+		// Diagnostics with invalid positions (<= 0) will be silently suppressed, so here we use 1.
+		res.Diagnostics = append(res.Diagnostics, analysis.Diagnostic{Pos: 1, Message: fmt.Sprintf("INCOMPATIBLE FACTS: %s", upstreamErr)})
+	}
+
+	return res, nil
 }
 
 type conflictHandler interface {
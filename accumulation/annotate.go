@@ -0,0 +1,214 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/inference"
+	"go.uber.org/nilaway/util"
+	"golang.org/x/tools/go/analysis"
+)
+
+// annotationSite is a site in the exported API of the package that can be annotated in the doc
+// comment of its declaration.
+type annotationSite struct {
+	// name is the name referring to the site in the annotations, e.g., `p` or `result 0`.
+	name string
+	key  annotation.Key
+	typ  types.Type
+}
+
+// annotate generates one diagnostic for each declaration in the exported API of the current
+// package (i.e., exported functions, struct fields, and global variables) whose nilability has
+// been determined by inference but not annotated in the source. Each diagnostic carries a
+// suggested fix that writes the inferred nilability back into the doc comment of the declaration
+// as an annotation (see config.AnnotateFlag), such that the package can then be switched to the
+// no-inference mode without changing the results.
+//
+// Only the annotations that make a difference in the no-inference mode are generated: `nilable`
+// for the sites that are nonnil by default (e.g., pointers), and, if requested via
+// config.AnnotateNonnilFlag, `nonnil` for the sites that are nilable by default (e.g., slices).
+func annotate(pass *analysis.Pass, conf *config.Config, inferredMap *inference.InferredMap) []analysis.Diagnostic {
+	var diagnostics []analysis.Diagnostic
+
+	// suggest adds a diagnostic with a suggested fix that inserts the annotations for the sites
+	// right before the node (i.e., at the end of its doc comment, if any).
+	suggest := func(node ast.Node, doc *ast.CommentGroup, desc string, sites []annotationSite) {
+		var nilable, nonnil []string
+		for _, site := range sites {
+			if util.TypeBarsNilness(site.typ) || annotation.IsAnnotatedInCommentGroup(doc, site.name) {
+				continue
+			}
+			isNilable, ok := inferredMap.CheckShallowAnn(site.key)
+			if !ok {
+				continue
+			}
+			isDefaultNilable := annotation.TypeIsDefaultNilable(site.typ)
+			switch {
+			case isNilable && !isDefaultNilable:
+				nilable = append(nilable, site.name)
+			case !isNilable && isDefaultNilable && conf.AnnotateNonnil:
+				nonnil = append(nonnil, site.name)
+			}
+		}
+
+		var comments []string
+		if len(nilable) > 0 {
+			comments = append(comments, annotation.FormatAnnotation(nilable, true /* isNilable */))
+		}
+		if len(nonnil) > 0 {
+			comments = append(comments, annotation.FormatAnnotation(nonnil, false /* isNilable */))
+		}
+		if len(comments) == 0 {
+			return
+		}
+
+		// The source is assumed to be gofmt-ed, so the declaration is indented with tabs only.
+		indent := strings.Repeat("\t", pass.Fset.Position(node.Pos()).Column-1)
+		var text strings.Builder
+		for _, c := range comments {
+			text.WriteString(c + "\n" + indent)
+		}
+		msg := fmt.Sprintf("Inferred annotations for %s: %s", desc, strings.Join(comments, " "))
+		diagnostics = append(diagnostics, analysis.Diagnostic{
+			Pos:     node.Pos(),
+			Message: msg,
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message:   msg,
+				TextEdits: []analysis.TextEdit{{Pos: node.Pos(), End: node.Pos(), NewText: []byte(text.String())}},
+			}},
+		})
+	}
+
+	for _, file := range pass.Files {
+		if !conf.IsFileInScope(file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if !decl.Name.IsExported() {
+					continue
+				}
+				funcObj, ok := pass.TypesInfo.ObjectOf(decl.Name).(*types.Func)
+				if !ok {
+					continue
+				}
+				sig := funcObj.Type().(*types.Signature)
+				var sites []annotationSite
+				for i := 0; i < sig.Params().Len(); i++ {
+					// Variadic parameters are annotated by their element types, skip them for simplicity.
+					if sig.Variadic() && i == sig.Params().Len()-1 {
+						continue
+					}
+					name := sig.Params().At(i).Name()
+					switch name {
+					case "_":
+						// Blank parameters cannot be referred to in the annotations.
+						continue
+					case "":
+						name = annotation.ParamAnnotationName(i)
+					}
+					sites = append(sites, annotationSite{
+						name: name,
+						key:  annotation.ParamKeyFromArgNum(funcObj, i),
+						typ:  sig.Params().At(i).Type(),
+					})
+				}
+				for i := 0; i < sig.Results().Len(); i++ {
+					name := sig.Results().At(i).Name()
+					if name == "" {
+						name = annotation.ResultAnnotationName(i)
+					}
+					sites = append(sites, annotationSite{
+						name: name,
+						key:  annotation.RetKeyFromRetNum(funcObj, i),
+						typ:  sig.Results().At(i).Type(),
+					})
+				}
+				suggest(decl, decl.Doc, fmt.Sprintf("function `%s`", decl.Name.Name), sites)
+
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					// Similar to the annotation parser, the annotations are read from the doc comment
+					// of the declaration for single declarations, or of the spec for grouped ones.
+					var node ast.Node = decl
+					doc := decl.Doc
+					grouped := len(decl.Specs) > 1
+
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						if decl.Tok != token.VAR {
+							continue
+						}
+						if grouped {
+							node, doc = spec, spec.Doc
+						}
+						var (
+							sites []annotationSite
+							names []string
+						)
+						for _, name := range spec.Names {
+							varObj, ok := pass.TypesInfo.ObjectOf(name).(*types.Var)
+							if !ok || !name.IsExported() {
+								continue
+							}
+							sites = append(sites, annotationSite{
+								name: name.Name,
+								key:  &annotation.GlobalVarAnnotationKey{VarDecl: varObj},
+								typ:  varObj.Type(),
+							})
+							names = append(names, name.Name)
+						}
+						suggest(node, doc, fmt.Sprintf("global variable(s) `%s`", strings.Join(names, "`, `")), sites)
+
+					case *ast.TypeSpec:
+						structType, ok := spec.Type.(*ast.StructType)
+						if !ok || !spec.Name.IsExported() {
+							continue
+						}
+						if grouped {
+							node, doc = spec, spec.Doc
+						}
+						var sites []annotationSite
+						for _, field := range structType.Fields.List {
+							for _, name := range field.Names {
+								fldObj, ok := pass.TypesInfo.ObjectOf(name).(*types.Var)
+								if !ok || !name.IsExported() {
+									continue
+								}
+								sites = append(sites, annotationSite{
+									name: name.Name,
+									key:  &annotation.FieldAnnotationKey{FieldDecl: fldObj},
+									typ:  fldObj.Type(),
+								})
+							}
+						}
+						suggest(node, doc, fmt.Sprintf("fields of struct `%s`", spec.Name.Name), sites)
+					}
+				}
+			}
+		}
+	}
+
+	return diagnostics
+}
@@ -0,0 +1,112 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"fmt"
+	"go/ast"
+
+	"go.uber.org/nilaway/util"
+)
+
+// This file contains the full triggers of the callbacks mechanism, which matches the annotations
+// of the params and results of a function-typed parameter (i.e., a callback) of a function with
+// those of the function passed as the argument for it at a call site. For example, for
+//
+//	// nilable(fn.param 0)
+//	func ForEach(items []*Item, fn func(*Item)) { ... }
+//
+//	func handle(item *Item) { ... }
+//
+// the call `ForEach(items, handle)` requires the param `item` of `handle` to be nilable, since
+// ForEach may call its callback with nil. Similar to the affiliations mechanism, this encodes the
+// "contravariance" of annotations for parameters and the "covariance" for results.
+//
+// Similarly, the params and results of function-typed struct fields can be annotated in the doc
+// comments of the struct types (e.g., `// nilable(fn.param 0)` for `type S struct { fn func(*Item) }`),
+// which are matched with those of the functions assigned to the fields (e.g., `S{fn: handle}` or
+// `s.fn = handle`) and checked at the calls of the fields (e.g., `s.fn(nil)`).
+
+// callbackDescription returns the description of a callback for the messages, where funcName is
+// empty if the callback is a struct field.
+func callbackDescription(callbackName, funcName string) string {
+	if funcName == "" {
+		return fmt.Sprintf("callback field `%s`", callbackName)
+	}
+	return fmt.Sprintf("callback `%s` of `%s()`", callbackName, funcName)
+}
+
+// FullTriggerForCallbackParamFlow takes the knowledge that `arg` is passed for the callback of
+// `callbackKey` at a call site, and returns a FullTrigger representing the assertion that the
+// callback can be called with a nilable argument (at the position of `callbackKey`) only if the
+// corresponding param of `arg` is nilable. `paramKey` is the key of the param of `arg`, which is
+// either a ParamAnnotationKey for a function, a RecvAnnotationKey for the receiver of a method
+// expression (e.g., `(*T).M`), or a CallbackAnnotationKey if `arg` itself is a callback of the
+// enclosing function.
+func FullTriggerForCallbackParamFlow(callbackKey *CallbackAnnotationKey, paramKey Key, arg ast.Expr) FullTrigger {
+	var consumer ConsumingAnnotationTrigger
+	switch paramKey := paramKey.(type) {
+	case *ParamAnnotationKey:
+		consumer = &ArgPass{TriggerIfNonNil: &TriggerIfNonNil{Ann: paramKey}}
+	case *RecvAnnotationKey:
+		consumer = &RecvPass{TriggerIfNonNil: &TriggerIfNonNil{Ann: paramKey}}
+	case *CallbackAnnotationKey:
+		consumer = &CallbackArgPass{TriggerIfNonNil: &TriggerIfNonNil{Ann: paramKey}}
+	default:
+		panic(fmt.Sprintf("Expected ParamAnnotationKey, RecvAnnotationKey or CallbackAnnotationKey but got: %T", paramKey))
+	}
+	return FullTrigger{
+		Producer: &ProduceTrigger{
+			Annotation: &CallbackParam{TriggerIfNilable: &TriggerIfNilable{Ann: callbackKey}},
+			Expr:       arg,
+		},
+		Consumer: &ConsumeTrigger{
+			Annotation:   consumer,
+			Expr:         arg,
+			Guards:       util.NoGuards(),
+			GuardMatched: false,
+		},
+	}
+}
+
+// FullTriggerForCallbackResultFlow takes the knowledge that `arg` is passed for the callback of
+// `callbackKey` at a call site, and returns a FullTrigger representing the assertion that the
+// corresponding result of `arg` can be nilable only if the callback can return a nilable result
+// (at the position of `callbackKey`). `retKey` is the key of the result of `arg`, which is either a
+// RetAnnotationKey for a function, or a CallbackAnnotationKey if `arg` itself is a callback of the
+// enclosing function.
+func FullTriggerForCallbackResultFlow(callbackKey *CallbackAnnotationKey, retKey Key, arg ast.Expr) FullTrigger {
+	var producer ProducingAnnotationTrigger
+	switch retKey := retKey.(type) {
+	case *RetAnnotationKey:
+		producer = &FuncReturn{TriggerIfNilable: &TriggerIfNilable{Ann: retKey}}
+	case *CallbackAnnotationKey:
+		producer = &CallbackResult{TriggerIfNilable: &TriggerIfNilable{Ann: retKey}}
+	default:
+		panic(fmt.Sprintf("Expected RetAnnotationKey or CallbackAnnotationKey but got: %T", retKey))
+	}
+	return FullTrigger{
+		Producer: &ProduceTrigger{
+			Annotation: producer,
+			Expr:       arg,
+		},
+		Consumer: &ConsumeTrigger{
+			Annotation:   &CallbackReturn{TriggerIfNonNil: &TriggerIfNonNil{Ann: callbackKey}},
+			Expr:         arg,
+			Guards:       util.NoGuards(),
+			GuardMatched: false,
+		},
+	}
+}
@@ -0,0 +1,342 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"go.uber.org/nilaway/util"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// constructorPrefix is the name prefix of the functions that we recognize as constructors, e.g.,
+// `NewServer` that returns a `*Server`.
+const constructorPrefix = "New"
+
+// fieldSet is a set of struct fields.
+type fieldSet map[*types.Var]bool
+
+// intersect returns the fields that are in both sets.
+func (s fieldSet) intersect(other fieldSet) fieldSet {
+	res := make(fieldSet)
+	for f := range s {
+		if other[f] {
+			res[f] = true
+		}
+	}
+	return res
+}
+
+// copy returns a shallow copy of the set.
+func (s fieldSet) copy() fieldSet {
+	res := make(fieldSet, len(s))
+	for f := range s {
+		res[f] = true
+	}
+	return res
+}
+
+// constructorInitializedFields returns the unexported fields of the struct types declared in the
+// package that are always set to non-nil values by all constructors of their types (i.e., the
+// `NewT` functions returning `T` or `*T` as their first results), and are never reassigned to nil
+// or left unset (i.e., zero values of `T` are never created) anywhere else in the package. Such
+// fields (e.g., the dependencies of a service struct) are treated as non-nil, which moves the
+// responsibility of proving their non-nilness from the reads of the fields to the assignments in
+// the constructors (hence the assertion analysis must consume the values assigned to these fields
+// in composite literals, even without struct initialization checks). The exported fields are
+// excluded since other packages may create values of `T` without setting them.
+func constructorInitializedFields(pass *analysis.Pass, files []*ast.File) map[*types.Var]bool {
+	fieldsByType := make(map[*types.Named]fieldSet)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			named := constructedType(pass, funcDecl)
+			if named == nil {
+				continue
+			}
+			fields := fieldsSetByConstructor(pass, funcDecl, named)
+			if prev, ok := fieldsByType[named]; ok {
+				fields = prev.intersect(fields)
+			}
+			fieldsByType[named] = fields
+		}
+	}
+	if len(fieldsByType) == 0 {
+		return nil
+	}
+
+	// Find the fields that are (re)assigned to nil or left unset outside the constructors of their
+	// types, e.g., `s.f = nil`, `T{f: nil}`, `&T{}`, `var t T`, or `new(T)`.
+	unset := make(fieldSet)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			// The values created in the constructors are checked by fieldsSetByConstructor.
+			var constructed *types.Named
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+				constructed = constructedType(pass, funcDecl)
+			}
+			// zeroed marks all fields of the type as unset if the type is one of the constructed
+			// types (other than the one constructed by the enclosing function).
+			zeroed := func(t types.Type) {
+				named, ok := t.(*types.Named)
+				if !ok || named == constructed {
+					return
+				}
+				for fld := range fieldsByType[named] {
+					unset[fld] = true
+				}
+			}
+
+			ast.Inspect(decl, func(node ast.Node) bool {
+				switch node := node.(type) {
+				case *ast.AssignStmt:
+					if len(node.Lhs) != len(node.Rhs) {
+						return true
+					}
+					for i, lhs := range node.Lhs {
+						sel, ok := astutil.Unparen(lhs).(*ast.SelectorExpr)
+						if !ok || !pass.TypesInfo.Types[node.Rhs[i]].IsNil() {
+							continue
+						}
+						if fld, ok := pass.TypesInfo.ObjectOf(sel.Sel).(*types.Var); ok && fld.IsField() {
+							unset[fld] = true
+						}
+					}
+				case *ast.KeyValueExpr:
+					key, ok := node.Key.(*ast.Ident)
+					if !ok || !pass.TypesInfo.Types[node.Value].IsNil() {
+						return true
+					}
+					if fld, ok := pass.TypesInfo.ObjectOf(key).(*types.Var); ok && fld.IsField() {
+						unset[fld] = true
+					}
+				case *ast.CompositeLit:
+					named, ok := pass.TypesInfo.TypeOf(node).(*types.Named)
+					if !ok || named == constructed {
+						return true
+					}
+					set := literalFields(pass, node)
+					for fld := range fieldsByType[named] {
+						if !set[fld] {
+							unset[fld] = true
+						}
+					}
+				case *ast.ValueSpec:
+					if len(node.Values) == 0 {
+						for _, name := range node.Names {
+							if obj := pass.TypesInfo.ObjectOf(name); obj != nil {
+								zeroed(obj.Type())
+							}
+						}
+					}
+				case *ast.CallExpr:
+					if ident, ok := astutil.Unparen(node.Fun).(*ast.Ident); ok && len(node.Args) == 1 {
+						if _, ok := pass.TypesInfo.ObjectOf(ident).(*types.Builtin); ok && ident.Name == "new" {
+							zeroed(pass.TypesInfo.TypeOf(node.Args[0]))
+						}
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	res := make(fieldSet)
+	for _, fields := range fieldsByType {
+		for fld := range fields {
+			if !fld.Exported() && !unset[fld] && !util.TypeBarsNilness(fld.Type()) {
+				res[fld] = true
+			}
+		}
+	}
+	return res
+}
+
+// literalFields returns the fields set to non-nil values in the composite literal of a struct type.
+func literalFields(pass *analysis.Pass, lit *ast.CompositeLit) fieldSet {
+	fields := make(fieldSet)
+	structType, ok := pass.TypesInfo.TypeOf(lit).Underlying().(*types.Struct)
+	if !ok {
+		return fields
+	}
+	for i, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok && !pass.TypesInfo.Types[kv.Value].IsNil() {
+				if fld, ok := pass.TypesInfo.ObjectOf(key).(*types.Var); ok {
+					fields[fld] = true
+				}
+			}
+			continue
+		}
+		if i < structType.NumFields() && !pass.TypesInfo.Types[elt].IsNil() {
+			fields[structType.Field(i)] = true
+		}
+	}
+	return fields
+}
+
+// constructedType returns the struct type constructed by the function if it is a constructor,
+// otherwise nil.
+func constructedType(pass *analysis.Pass, funcDecl *ast.FuncDecl) *types.Named {
+	if funcDecl.Recv != nil || funcDecl.Body == nil || !strings.HasPrefix(funcDecl.Name.Name, constructorPrefix) {
+		return nil
+	}
+	funcObj, ok := pass.TypesInfo.ObjectOf(funcDecl.Name).(*types.Func)
+	if !ok || util.FuncNumResults(funcObj) == 0 {
+		return nil
+	}
+
+	t := funcObj.Type().(*types.Signature).Results().At(0).Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Pkg() != pass.Pkg {
+		return nil
+	}
+	if _, ok := named.Underlying().(*types.Struct); !ok {
+		return nil
+	}
+	return named
+}
+
+// fieldsSetByConstructor returns the fields that are set to non-nil values on every path of the
+// constructor that returns the constructed value. We only recognize the constructed values that
+// are returned as composite literals directly, or as local variables initialized with composite
+// literals whose fields are further assigned at the top level of the function body, e.g.,
+//
+//	s := &Server{logger: logger}
+//	s.db = db
+//	return s, nil
+func fieldsSetByConstructor(pass *analysis.Pass, funcDecl *ast.FuncDecl, named *types.Named) fieldSet {
+	funcObj := pass.TypesInfo.ObjectOf(funcDecl.Name).(*types.Func)
+
+	// constructedFields returns the fields set to non-nil values in the composite literal, or false
+	// if the expression is not a composite literal of the constructed type.
+	constructedFields := func(expr ast.Expr) (fieldSet, bool) {
+		expr = astutil.Unparen(expr)
+		if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			expr = astutil.Unparen(unary.X)
+		}
+		lit, ok := expr.(*ast.CompositeLit)
+		if !ok || !types.Identical(pass.TypesInfo.TypeOf(lit), named) {
+			return nil, false
+		}
+		return literalFields(pass, lit), true
+	}
+
+	// varFields maps the local variables holding the constructed values to the fields set so far.
+	varFields := make(map[*types.Var]fieldSet)
+	varOf := func(expr ast.Expr) *types.Var {
+		if ident, ok := astutil.Unparen(expr).(*ast.Ident); ok {
+			if v, ok := pass.TypesInfo.ObjectOf(ident).(*types.Var); ok {
+				return v
+			}
+		}
+		return nil
+	}
+	assign := func(lhs, rhs ast.Expr) {
+		if v := varOf(lhs); v != nil {
+			if fields, ok := constructedFields(rhs); ok {
+				varFields[v] = fields
+			} else {
+				delete(varFields, v)
+			}
+			return
+		}
+		if sel, ok := astutil.Unparen(lhs).(*ast.SelectorExpr); ok {
+			if fields, ok := varFields[varOf(sel.X)]; ok && !pass.TypesInfo.Types[rhs].IsNil() {
+				if fld, ok := pass.TypesInfo.ObjectOf(sel.Sel).(*types.Var); ok {
+					fields[fld] = true
+				}
+			}
+		}
+	}
+
+	var res fieldSet
+	returnedFields := func(ret *ast.ReturnStmt) fieldSet {
+		if len(ret.Results) == 0 {
+			return make(fieldSet)
+		}
+		result := ret.Results[0]
+		if fields, ok := constructedFields(result); ok {
+			return fields
+		}
+		if fields, ok := varFields[varOf(result)]; ok {
+			return fields.copy()
+		}
+		return make(fieldSet)
+	}
+
+	for _, stmt := range funcDecl.Body.List {
+		// Inspect the return statements (possibly nested) with the fields set so far.
+		ast.Inspect(stmt, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.AssignStmt:
+				// Conservatively stop tracking the variables that are conditionally reassigned.
+				for _, lhs := range node.Lhs {
+					delete(varFields, varOf(lhs))
+				}
+			case *ast.ReturnStmt:
+				// A nil result guarded by an error is never used, hence it does not matter.
+				if len(node.Results) > 1 && util.FuncIsErrReturning(funcObj) &&
+					pass.TypesInfo.Types[node.Results[0]].IsNil() {
+					return false
+				}
+				fields := returnedFields(node)
+				if res != nil {
+					fields = res.intersect(fields)
+				}
+				res = fields
+				return false
+			}
+			return true
+		})
+
+		// Track the assignments at the top level of the function body, which are always executed
+		// before the following statements.
+		switch stmt := stmt.(type) {
+		case *ast.AssignStmt:
+			if len(stmt.Lhs) == len(stmt.Rhs) {
+				for i := range stmt.Lhs {
+					assign(stmt.Lhs[i], stmt.Rhs[i])
+				}
+			}
+		case *ast.DeclStmt:
+			if genDecl, ok := stmt.Decl.(*ast.GenDecl); ok && genDecl.Tok == token.VAR {
+				for _, spec := range genDecl.Specs {
+					if spec, ok := spec.(*ast.ValueSpec); ok && len(spec.Names) == len(spec.Values) {
+						for i := range spec.Names {
+							assign(spec.Names[i], spec.Values[i])
+						}
+					}
+				}
+			}
+		}
+	}
+	if res == nil {
+		return make(fieldSet)
+	}
+	return res
+}
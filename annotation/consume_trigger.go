@@ -518,6 +518,88 @@ func (s SliceAccessPrestring) String() string {
 	return sb.String()
 }
 
+// FuncValueCall is when a value of function type (e.g., a callback stored in a variable, field, or
+// map) flows to a point where it is called, and thus must be non-nil
+type FuncValueCall struct {
+	*ConsumeTriggerTautology
+}
+
+// equals returns true if the passed ConsumingAnnotationTrigger is equal to this one
+func (f *FuncValueCall) equals(other ConsumingAnnotationTrigger) bool {
+	if other, ok := other.(*FuncValueCall); ok {
+		return f.ConsumeTriggerTautology.equals(other.ConsumeTriggerTautology)
+	}
+	return false
+}
+
+// Copy returns a deep copy of this ConsumingAnnotationTrigger
+func (f *FuncValueCall) Copy() ConsumingAnnotationTrigger {
+	copyConsumer := *f
+	copyConsumer.ConsumeTriggerTautology = f.ConsumeTriggerTautology.Copy().(*ConsumeTriggerTautology)
+	return &copyConsumer
+}
+
+// Prestring returns this FuncValueCall as a Prestring
+func (f *FuncValueCall) Prestring() Prestring {
+	return FuncValueCallPrestring{
+		AssignmentStr: f.assignmentFlow.String(),
+	}
+}
+
+// FuncValueCallPrestring is a Prestring storing the needed information to compactly encode a FuncValueCall
+type FuncValueCallPrestring struct {
+	AssignmentStr string
+}
+
+func (f FuncValueCallPrestring) String() string {
+	var sb strings.Builder
+	sb.WriteString("called as a function")
+	sb.WriteString(f.AssignmentStr)
+	return sb.String()
+}
+
+// TypedNilInInterface is when a value of a concrete pointer type flows to a point where it is
+// implicitly converted to an interface (e.g., returned as an `error`) that is compared against nil.
+// A nil pointer stored in an interface makes the interface itself non-nil (a "typed nil"), so the
+// comparison would not behave as expected, and the value must be non-nil
+type TypedNilInInterface struct {
+	*ConsumeTriggerTautology
+}
+
+// equals returns true if the passed ConsumingAnnotationTrigger is equal to this one
+func (t *TypedNilInInterface) equals(other ConsumingAnnotationTrigger) bool {
+	if other, ok := other.(*TypedNilInInterface); ok {
+		return t.ConsumeTriggerTautology.equals(other.ConsumeTriggerTautology)
+	}
+	return false
+}
+
+// Copy returns a deep copy of this ConsumingAnnotationTrigger
+func (t *TypedNilInInterface) Copy() ConsumingAnnotationTrigger {
+	copyConsumer := *t
+	copyConsumer.ConsumeTriggerTautology = t.ConsumeTriggerTautology.Copy().(*ConsumeTriggerTautology)
+	return &copyConsumer
+}
+
+// Prestring returns this TypedNilInInterface as a Prestring
+func (t *TypedNilInInterface) Prestring() Prestring {
+	return TypedNilInInterfacePrestring{
+		AssignmentStr: t.assignmentFlow.String(),
+	}
+}
+
+// TypedNilInInterfacePrestring is a Prestring storing the needed information to compactly encode a TypedNilInInterface
+type TypedNilInInterfacePrestring struct {
+	AssignmentStr string
+}
+
+func (t TypedNilInInterfacePrestring) String() string {
+	var sb strings.Builder
+	sb.WriteString("stored in an interface compared against nil (typed nil makes it non-nil)")
+	sb.WriteString(t.AssignmentStr)
+	return sb.String()
+}
+
 // FldAccess is when a value flows to a point where a field of it is accessed, and so it must be non-nil
 type FldAccess struct {
 	*ConsumeTriggerTautology
@@ -1067,6 +1149,100 @@ func (m MethodParamFromInterfacePrestring) String() string {
 	return sb.String()
 }
 
+// CallbackArgPass is when a value flows to a point where it is passed as an argument to a
+// function-typed parameter (i.e., a callback) of the enclosing function
+type CallbackArgPass struct {
+	*TriggerIfNonNil
+}
+
+// equals returns true if the passed ConsumingAnnotationTrigger is equal to this one
+func (c *CallbackArgPass) equals(other ConsumingAnnotationTrigger) bool {
+	if other, ok := other.(*CallbackArgPass); ok {
+		return c.TriggerIfNonNil.equals(other.TriggerIfNonNil)
+	}
+	return false
+}
+
+// Copy returns a deep copy of this ConsumingAnnotationTrigger
+func (c *CallbackArgPass) Copy() ConsumingAnnotationTrigger {
+	copyConsumer := *c
+	copyConsumer.TriggerIfNonNil = c.TriggerIfNonNil.Copy().(*TriggerIfNonNil)
+	return &copyConsumer
+}
+
+// Prestring returns this CallbackArgPass as a Prestring
+func (c *CallbackArgPass) Prestring() Prestring {
+	key := c.Ann.(*CallbackAnnotationKey)
+	return CallbackArgPassPrestring{
+		ParamNum:      key.Num,
+		CallbackName:  key.CallbackName(),
+		FuncName:      key.FuncName(),
+		AssignmentStr: c.assignmentFlow.String(),
+	}
+}
+
+// CallbackArgPassPrestring is a Prestring storing the needed information to compactly encode a CallbackArgPass
+type CallbackArgPassPrestring struct {
+	ParamNum      int
+	CallbackName  string
+	FuncName      string
+	AssignmentStr string
+}
+
+func (c CallbackArgPassPrestring) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("passed as param %d to %s", c.ParamNum, callbackDescription(c.CallbackName, c.FuncName)))
+	sb.WriteString(c.AssignmentStr)
+	return sb.String()
+}
+
+// CallbackReturn is when a value flows to a point where it is returned from a function passed as
+// a function-typed parameter (i.e., a callback) of another function
+type CallbackReturn struct {
+	*TriggerIfNonNil
+}
+
+// equals returns true if the passed ConsumingAnnotationTrigger is equal to this one
+func (c *CallbackReturn) equals(other ConsumingAnnotationTrigger) bool {
+	if other, ok := other.(*CallbackReturn); ok {
+		return c.TriggerIfNonNil.equals(other.TriggerIfNonNil)
+	}
+	return false
+}
+
+// Copy returns a deep copy of this ConsumingAnnotationTrigger
+func (c *CallbackReturn) Copy() ConsumingAnnotationTrigger {
+	copyConsumer := *c
+	copyConsumer.TriggerIfNonNil = c.TriggerIfNonNil.Copy().(*TriggerIfNonNil)
+	return &copyConsumer
+}
+
+// Prestring returns this CallbackReturn as a Prestring
+func (c *CallbackReturn) Prestring() Prestring {
+	key := c.Ann.(*CallbackAnnotationKey)
+	return CallbackReturnPrestring{
+		RetNum:        key.Num,
+		CallbackName:  key.CallbackName(),
+		FuncName:      key.FuncName(),
+		AssignmentStr: c.assignmentFlow.String(),
+	}
+}
+
+// CallbackReturnPrestring is a Prestring storing the needed information to compactly encode a CallbackReturn
+type CallbackReturnPrestring struct {
+	RetNum        int
+	CallbackName  string
+	FuncName      string
+	AssignmentStr string
+}
+
+func (c CallbackReturnPrestring) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("returned as result %d from %s", c.RetNum, callbackDescription(c.CallbackName, c.FuncName)))
+	sb.WriteString(c.AssignmentStr)
+	return sb.String()
+}
+
 // DuplicateReturnConsumer duplicates a given consume trigger, assuming the given consumer trigger
 // is for a UseAsReturn annotation.
 func DuplicateReturnConsumer(t *ConsumeTrigger, location token.Position) *ConsumeTrigger {
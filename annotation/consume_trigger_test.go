@@ -31,6 +31,8 @@ var initStructsConsumingAnnotationTrigger = []any{
 	&MapAccess{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
 	&MapWrittenTo{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
 	&SliceAccess{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
+	&FuncValueCall{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
+	&TypedNilInInterface{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
 	&FldAccess{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
 	&UseAsErrorResult{TriggerIfNonNil: &TriggerIfNonNil{Ann: newMockKey()}},
 	&FldAssign{TriggerIfNonNil: &TriggerIfNonNil{Ann: newMockKey()}},
@@ -40,6 +42,8 @@ var initStructsConsumingAnnotationTrigger = []any{
 	&RecvPass{TriggerIfNonNil: &TriggerIfNonNil{Ann: newMockKey()}},
 	&InterfaceResultFromImplementation{TriggerIfNonNil: &TriggerIfNonNil{Ann: newMockKey()}},
 	&MethodParamFromInterface{TriggerIfNonNil: &TriggerIfNonNil{Ann: newMockKey()}},
+	&CallbackArgPass{TriggerIfNonNil: &TriggerIfNonNil{Ann: newMockKey()}},
+	&CallbackReturn{TriggerIfNonNil: &TriggerIfNonNil{Ann: newMockKey()}},
 	&UseAsReturn{TriggerIfNonNil: &TriggerIfNonNil{Ann: newMockKey()}},
 	&UseAsFldOfReturn{TriggerIfNonNil: &TriggerIfNonNil{Ann: newMockKey()}},
 	&SliceAssign{TriggerIfDeepNonNil: &TriggerIfDeepNonNil{Ann: newMockKey()}},
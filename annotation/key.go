@@ -385,6 +385,156 @@ func RetKeyFromRetNum(fdecl *types.Func, retNum int) *RetAnnotationKey {
 	}
 }
 
+// CallbackAnnotationKey allows the Lookup of the Annotation of a parameter or a result of a
+// function-typed parameter or struct field (i.e., a callback) in the Annotation map. For example,
+// for `func ForEach(items []*Item, fn func(*Item))`, the key for param 0 of callback `fn` stores
+// whether ForEach may call `fn` with a nil argument. Only construct these using
+// CallbackKeyFromParamNum, CallbackKeyFromRetNum, FieldCallbackKeyFromParamNum, and
+// FieldCallbackKeyFromRetNum.
+type CallbackAnnotationKey struct {
+	FuncDecl *types.Func
+	// ParamNum is the index of the function-typed parameter of FuncDecl.
+	ParamNum int
+	// FieldDecl is the function-typed struct field if the callback is a field (in which case
+	// FuncDecl and ParamNum are unused), and nil otherwise.
+	FieldDecl *types.Var
+	// IsResult indicates whether this key is for a result (instead of a parameter) of the callback.
+	IsResult bool
+	// Num is the index of the parameter or the result of the callback.
+	Num int
+}
+
+// CallbackKeyFromParamNum returns a new instance of CallbackAnnotationKey for the num-th parameter
+// of the paramNum-th parameter (which must be function-typed) of the passed function declaration
+func CallbackKeyFromParamNum(fdecl *types.Func, paramNum int, num int) *CallbackAnnotationKey {
+	return &CallbackAnnotationKey{
+		FuncDecl: fdecl,
+		ParamNum: paramNum,
+		Num:      callbackParamNum(CallbackSignature(fdecl, paramNum), num),
+	}
+}
+
+// CallbackKeyFromRetNum returns a new instance of CallbackAnnotationKey for the retNum-th result of
+// the paramNum-th parameter (which must be function-typed) of the passed function declaration
+func CallbackKeyFromRetNum(fdecl *types.Func, paramNum int, retNum int) *CallbackAnnotationKey {
+	return &CallbackAnnotationKey{
+		FuncDecl: fdecl,
+		ParamNum: paramNum,
+		IsResult: true,
+		Num:      retNum,
+	}
+}
+
+// FieldCallbackKeyFromParamNum returns a new instance of CallbackAnnotationKey for the num-th
+// parameter of the passed struct field, which must be function-typed
+func FieldCallbackKeyFromParamNum(fld *types.Var, num int) *CallbackAnnotationKey {
+	return &CallbackAnnotationKey{
+		FieldDecl: fld,
+		Num:       callbackParamNum(FieldCallbackSignature(fld), num),
+	}
+}
+
+// FieldCallbackKeyFromRetNum returns a new instance of CallbackAnnotationKey for the retNum-th
+// result of the passed struct field, which must be function-typed
+func FieldCallbackKeyFromRetNum(fld *types.Var, retNum int) *CallbackAnnotationKey {
+	return &CallbackAnnotationKey{
+		FieldDecl: fld,
+		IsResult:  true,
+		Num:       retNum,
+	}
+}
+
+// callbackParamNum "rounds down" the argument number to the variadic param for variadic callbacks.
+func callbackParamNum(sig *types.Signature, num int) int {
+	if sig.Variadic() && num >= sig.Params().Len()-1 {
+		return sig.Params().Len() - 1
+	}
+	return num
+}
+
+// CallbackSignature returns the signature of the paramNum-th parameter of the passed function
+// declaration if it is function-typed (i.e., a callback), and nil otherwise
+func CallbackSignature(fdecl *types.Func, paramNum int) *types.Signature {
+	params := fdecl.Type().(*types.Signature).Params()
+	if paramNum >= params.Len() {
+		return nil
+	}
+	sig, _ := params.At(paramNum).Type().Underlying().(*types.Signature)
+	return sig
+}
+
+// FieldCallbackSignature returns the signature of the passed struct field if it is function-typed
+// (i.e., a callback), and nil otherwise
+func FieldCallbackSignature(fld *types.Var) *types.Signature {
+	if !fld.IsField() {
+		return nil
+	}
+	sig, _ := fld.Type().Underlying().(*types.Signature)
+	return sig
+}
+
+// CallbackName returns the name of the function-typed parameter or field, if named, or a
+// placeholder string otherwise
+func (ck *CallbackAnnotationKey) CallbackName() string {
+	if ck.FieldDecl != nil {
+		return ck.FieldDecl.Name()
+	}
+	if name := ck.FuncDecl.Type().(*types.Signature).Params().At(ck.ParamNum).Name(); name != "" {
+		return name
+	}
+	return fmt.Sprintf("<unnamed param %d>", ck.ParamNum)
+}
+
+// FuncName returns the name of the function whose parameter is the callback, or an empty string
+// if the callback is a struct field
+func (ck *CallbackAnnotationKey) FuncName() string {
+	if ck.FieldDecl != nil {
+		return ""
+	}
+	return ck.FuncDecl.Name()
+}
+
+// Lookup looks this key up in the passed map, returning a Val
+func (ck *CallbackAnnotationKey) Lookup(annMap Map) (Val, bool) {
+	if val, ok := annMap.CheckCallbackAnn(ck); ok {
+		return val, true
+	}
+	return nonAnnotatedDefault, false
+}
+
+// Object returns the types.Object that this annotation can best be interpreted as annotating
+func (ck *CallbackAnnotationKey) Object() types.Object {
+	if ck.FieldDecl != nil {
+		return ck.FieldDecl
+	}
+	return ck.FuncDecl
+}
+
+// equals returns true if the passed key is equal to this key
+func (ck *CallbackAnnotationKey) equals(other Key) bool {
+	if other, ok := other.(*CallbackAnnotationKey); ok {
+		return *ck == *other
+	}
+	return false
+}
+
+func (ck *CallbackAnnotationKey) copy() Key {
+	copyKey := *ck
+	return &copyKey
+}
+
+func (ck *CallbackAnnotationKey) String() string {
+	kind := "Param"
+	if ck.IsResult {
+		kind = "Result"
+	}
+	if ck.FieldDecl != nil {
+		return fmt.Sprintf("%s %d of Callback Field %s", kind, ck.Num, ck.FieldDecl.Name())
+	}
+	return fmt.Sprintf("%s %d of Callback %d of Function %s",
+		kind, ck.Num, ck.ParamNum, ck.FuncDecl.Name())
+}
+
 // TypeNameAnnotationKey allows the Lookup of a named type annotations in the Annotation Map
 type TypeNameAnnotationKey struct {
 	TypeDecl *types.TypeName
@@ -29,6 +29,7 @@ var initStructsKey = []any{
 	&ParamAnnotationKey{},
 	&CallSiteRetAnnotationKey{},
 	&RetAnnotationKey{},
+	&CallbackAnnotationKey{},
 	&TypeNameAnnotationKey{},
 	&GlobalVarAnnotationKey{},
 	&RecvAnnotationKey{},
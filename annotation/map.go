@@ -37,6 +37,7 @@ type Map interface {
 	CheckGlobalVarAnn(*types.Var) (Val, bool)
 	CheckFuncCallSiteParamAnn(*CallSiteParamAnnotationKey) (Val, bool)
 	CheckFuncCallSiteRetAnn(*CallSiteRetAnnotationKey) (Val, bool)
+	CheckCallbackAnn(*CallbackAnnotationKey) (Val, bool)
 }
 
 // Val is a possible value of an Annotation
@@ -134,7 +135,8 @@ func (a Val) makeDeepNonNil(isFinalVal bool) Val {
 // it is used; canonically, declarations are identified with the identifier used at the site
 // of the declaration
 //
-// TODO: handle annotations for anonymous functions too
+// The annotations for anonymous functions are read from their doc comments (see FuncLitDocs), and
+// stored for their fake function declarations once they are known (see AddFuncLits).
 type ObservedMap struct {
 	// this maps fields by the identifier declaring them to their Annotation type
 	fieldAnnMap map[*types.Var]Val
@@ -163,6 +165,75 @@ type ObservedMap struct {
 	// funcCallSiteRetAnnMap maps a function call site to a slice with the annotations of its
 	// duplicated returns at the call site.
 	funcCallSiteRetAnnMap map[CallSite][]Val
+
+	// callbackAnnMap maps the params and results of the function-typed params (i.e., callbacks)
+	// of functions to their annotations.
+	callbackAnnMap map[CallbackAnnotationKey]Val
+
+	// ctorInitFields stores the fields that are not annotated but always set by the constructors of
+	// their struct types, hence are treated as nonnil (see constructorInitializedFields).
+	ctorInitFields map[*types.Var]bool
+
+	// funcLitAnnMap maps the anonymous functions with doc comments to the annotations read from
+	// them, which are stored for their fake function declarations by AddFuncLits.
+	funcLitAnnMap map[*ast.FuncLit]funcLitAnn
+}
+
+// funcLitAnn stores the annotations read from the doc comment of an anonymous function. Only the
+// params and results of the anonymous function itself can be annotated, but not the fake params
+// representing the closure variables.
+type funcLitAnn struct {
+	params  []Val
+	results []Val
+	// callbacks are keyed without the function declaration, which is the fake one of the
+	// anonymous function.
+	callbacks map[CallbackAnnotationKey]Val
+}
+
+// AddFuncLits stores the annotations read from the doc comments of the anonymous functions for
+// their fake function declarations, which are created separately by the anonymous function
+// support of the assertion analyzers. It must be called before this map is observed.
+func (m *ObservedMap) AddFuncLits(fakeFuncObjs map[*ast.FuncLit]*types.Func) {
+	for funcLit, ann := range m.funcLitAnnMap {
+		funcObj, ok := fakeFuncObjs[funcLit]
+		if !ok {
+			continue
+		}
+		m.funcParamAnnMap[funcObj] = ann.params
+		m.funcRetAnnMap[funcObj] = ann.results
+		for key, val := range ann.callbacks {
+			key.FuncDecl = funcObj
+			m.callbackAnnMap[key] = val
+		}
+	}
+}
+
+// AddNonNilResults marks the results of the functions declared in this package as nonnil if they
+// are annotated to escape from the guarding by their last (error or ok) results (e.g.,
+// `always-nonnil(result 0)`, see package nonnilresults), since they must be nonnil regardless of
+// the last results. The explicit nilability annotations of the results take precedence. It must be
+// called before this map is observed.
+func (m *ObservedMap) AddNonNilResults(isUnguarded func(funcObj *types.Func, i int) bool) {
+	for funcObj, vals := range m.funcRetAnnMap {
+		for i, val := range vals {
+			if isUnguarded(funcObj, i) {
+				vals[i] = val.makeNonNil(true)
+			}
+		}
+	}
+}
+
+// IsConstructorInitialized returns true iff the key is the site of a field that is treated as
+// nonnil since it is always set by the constructors of its struct type.
+func (m *ObservedMap) IsConstructorInitialized(key Key) bool {
+	fldKey, ok := key.(*FieldAnnotationKey)
+	return ok && m.ctorInitFields[fldKey.FieldDecl]
+}
+
+// ConstructorInitializedFields returns the fields in the package that are treated as nonnil since
+// they are always set by the constructors of their struct types.
+func (m *ObservedMap) ConstructorInitializedFields() map[*types.Var]bool {
+	return m.ctorInitFields
 }
 
 // CallSite uniquely identifies a function call. It contains the called function object and the
@@ -233,6 +304,11 @@ func (m *ObservedMap) Range(op func(key Key, isDeep bool, val bool), setSitesOnl
 			callOpOnKeyVal(NewCallSiteRetKey(callSite.Fun, i, callSite.Location), val)
 		}
 	}
+
+	for key, val := range m.callbackAnnMap {
+		key := key
+		callOpOnKeyVal(&key, val)
+	}
 }
 
 // defaults for anonymous functions and structs (ones for which definitions just can't be found
@@ -257,8 +333,13 @@ const resultTemplateStr = "result %s"
 
 var resultRegexStr = fmt.Sprintf(resultTemplateStr, "[0-9]+")
 
-var tokenRegexStr = fmt.Sprintf("((%s)|(%s)|(%s))",
-	identRegexStr, paramRegexStr, resultRegexStr)
+// callbackTemplateStr refers to a param or a result of a function-typed param, e.g., `fn.param 0`.
+const callbackTemplateStr = "%s.%s"
+
+var callbackRegexStr = fmt.Sprintf("%s\\.((%s)|(%s))", identRegexStr, paramRegexStr, resultRegexStr)
+
+var tokenRegexStr = fmt.Sprintf("((%s)|(%s)|(%s)|(%s))",
+	callbackRegexStr, identRegexStr, paramRegexStr, resultRegexStr)
 
 func paramStr(i int) string {
 	return fmt.Sprintf(paramTemplateStr, fmt.Sprintf("%d", i))
@@ -268,10 +349,19 @@ func resultStr(i int) string {
 	return fmt.Sprintf(resultTemplateStr, fmt.Sprintf("%d", i))
 }
 
+func callbackStr(name string, s string) string {
+	return fmt.Sprintf(callbackTemplateStr, name, s)
+}
+
 var deepIdentRegexStr = fmt.Sprintf("((\\*%s)|(%s\\[\\])|(<-%s)|%s)",
 	tokenRegexStr, tokenRegexStr, tokenRegexStr, tokenRegexStr)
-var seqRegexStr = fmt.Sprintf("%s\\((\\s*%s\\s*(%s\\s*%s\\s*)*)\\)",
+
+// The keyword must not be preceded by a word character or a hyphen, such that, e.g., the
+// `always-nonnil(...)` and `error-nonnil(...)` annotations (see package nonnilresults) are not
+// matched as `nonnil(...)`.
+var seqRegexStr = fmt.Sprintf("(?:^|[^\\w-])%s\\((\\s*%s\\s*(%s\\s*%s\\s*)*)\\)",
 	annotationKeyword, deepIdentRegexStr, sep, deepIdentRegexStr)
+
 var seqRegex = regexp.MustCompile(seqRegexStr)
 
 type nilabilitySet map[string]Val
@@ -347,6 +437,36 @@ func nilabilityFromCommentGroup(group *ast.CommentGroup) nilabilitySet {
 	return set
 }
 
+// IsAnnotatedInCommentGroup returns true iff the passed name (e.g., `p` or `result 0`) is
+// explicitly annotated either nilable or nonnil in the passed CommentGroup.
+func IsAnnotatedInCommentGroup(group *ast.CommentGroup, name string) bool {
+	val, ok := nilabilityFromCommentGroup(group)[name]
+	return ok && val.IsNilableSet
+}
+
+// ParamAnnotationName returns the name used in annotations to refer to the i-th parameter of a
+// function, if the parameter is unnamed (e.g., `param 0`).
+func ParamAnnotationName(i int) string {
+	return paramStr(i)
+}
+
+// ResultAnnotationName returns the name used in annotations to refer to the i-th result of a
+// function (e.g., `result 0`).
+func ResultAnnotationName(i int) string {
+	return resultStr(i)
+}
+
+// FormatAnnotation returns the comment annotating the passed names as nilable (or nonnil if
+// isNilable is false), e.g., `// nilable(p, result 0)`, which will be parsed back by
+// nilabilityFromCommentGroup.
+func FormatAnnotation(names []string, isNilable bool) string {
+	keyword := nonNilKeyword
+	if isNilable {
+		keyword = nilableKeyword
+	}
+	return fmt.Sprintf("// %s(%s)", keyword, strings.Join(names, sep+" "))
+}
+
 // TypeIsDefaultNilable takes a type and returns true iff we assume default nilability for that
 // type - in contrast to the remaining cases, in which we assume default non-nil.
 func TypeIsDefaultNilable(t types.Type) bool {
@@ -439,6 +559,7 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 	funcObjToFuncDecl := make(map[*types.Func]*ast.FuncDecl)
 	funcCallSiteParamAnnMap := make(map[CallSite][]ArgLocAndVal)
 	funcCallSiteRetAnnMap := make(map[CallSite][]Val)
+	callbackAnnMap := make(map[CallbackAnnotationKey]Val)
 
 	typeOf := func(expr ast.Expr) types.Type {
 		return pass.TypesInfo.Types[expr].Type
@@ -501,6 +622,41 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 		return annVals
 	}
 
+	// for a callback (i.e., a function-typed param or field) of the signature, look up the
+	// annotations of its params and results (e.g., `fn.param 0`) in the docstring
+	readCallbackSignature := func(name string, sig *types.Signature, set nilabilitySet,
+		paramKey, retKey func(j int) *CallbackAnnotationKey, callbackAnnMap map[CallbackAnnotationKey]Val) {
+		for j := 0; j < sig.Params().Len(); j++ {
+			paramType := sig.Params().At(j).Type()
+			if sig.Variadic() && j == sig.Params().Len()-1 {
+				// similar to the variadic params of functions, the values passed are of the
+				// element type
+				paramType = paramType.(*types.Slice).Elem()
+			}
+			callbackAnnMap[*paramKey(j)] = set.checkNilability(callbackStr(name, paramStr(j)), paramType)
+		}
+		for j := 0; j < sig.Results().Len(); j++ {
+			callbackAnnMap[*retKey(j)] = set.checkNilability(callbackStr(name, resultStr(j)), sig.Results().At(j).Type())
+		}
+	}
+
+	// for a function declaration of the signature, look up the annotations of the params and
+	// results of its function-typed params (e.g., `fn.param 0`) in the docstring
+	readCallbackAnnotations := func(funcObj *types.Func, funcSig *types.Signature, set nilabilitySet,
+		callbackAnnMap map[CallbackAnnotationKey]Val) {
+		params := funcSig.Params()
+		for i := 0; i < params.Len(); i++ {
+			sig, ok := params.At(i).Type().Underlying().(*types.Signature)
+			if !ok {
+				continue
+			}
+			readCallbackSignature(params.At(i).Name(), sig, set,
+				func(j int) *CallbackAnnotationKey { return CallbackKeyFromParamNum(funcObj, i, j) },
+				func(j int) *CallbackAnnotationKey { return CallbackKeyFromRetNum(funcObj, i, j) },
+				callbackAnnMap)
+		}
+	}
+
 	readRecvAnnotations := func(decl *ast.FuncDecl, set nilabilitySet) Val {
 		if decl.Recv != nil {
 			if len(decl.Recv.List) > 1 {
@@ -521,6 +677,7 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 					funcParamAnnMap[funcObj] = accFromFieldList(set, decl.Type.Params, true, false)
 					funcRetAnnMap[funcObj] = accFromFieldList(set, decl.Type.Results, false, false)
 					funcRecvAnnMap[funcObj] = readRecvAnnotations(decl, set)
+					readCallbackAnnotations(funcObj, funcObj.Type().(*types.Signature), set, callbackAnnMap)
 					// store the mapping from the function object to the ast node.
 					funcObjToFuncDecl[funcObj] = decl
 				case *ast.GenDecl:
@@ -558,8 +715,8 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 
 							docNilabilitySet := readDocNilabilitySet(spec.Doc)
 
-							// readDeepNilability is called on type declarations of maps, slices, and
-							// pointers to see if their contained values are nilable
+							// readDeepNilability is called on type declarations of maps, slices, arrays,
+							// pointers, and channels to see if their contained values are nilable
 							readDeepNilability := func() {
 								typeName := pass.TypesInfo.ObjectOf(spec.Name).(*types.TypeName)
 								deepTypeAnnMap[typeName] =
@@ -571,8 +728,25 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 								case *ast.StructType:
 									for _, field := range typeVal.Fields.List {
 										for _, name := range field.Names {
-											fieldAnnMap[pass.TypesInfo.ObjectOf(name).(*types.Var)] =
-												docNilabilitySet.checkNilability(name.Name, typeOf(field.Type))
+											fld := pass.TypesInfo.ObjectOf(name).(*types.Var)
+											fieldAnnMap[fld] = docNilabilitySet.checkNilability(name.Name, typeOf(field.Type))
+											// the params and results of the function-typed fields (i.e.,
+											// callbacks) are annotated in the same docstring
+											if sig := FieldCallbackSignature(fld); sig != nil {
+												readCallbackSignature(name.Name, sig, docNilabilitySet,
+													func(j int) *CallbackAnnotationKey { return FieldCallbackKeyFromParamNum(fld, j) },
+													func(j int) *CallbackAnnotationKey { return FieldCallbackKeyFromRetNum(fld, j) },
+													callbackAnnMap)
+											}
+										}
+									}
+									// embedded fields have no names in the AST, so we read them from the type
+									// instead, where they are named after their types (e.g., `Base` for `*Base`)
+									if structType, ok := typeOf(typeVal).(*types.Struct); ok {
+										for i := 0; i < structType.NumFields(); i++ {
+											if fld := structType.Field(i); fld.Embedded() {
+												fieldAnnMap[fld] = docNilabilitySet.checkNilability(fld.Name(), fld.Type())
+											}
 										}
 									}
 								case *ast.InterfaceType:
@@ -605,9 +779,15 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 								case *ast.SelectorExpr: // type alias - do nothing
 								case *ast.FuncType: // function type - do nothing (for now)
 								case *ast.ChanType:
-									// TODO - treat channel types as deeply nilable at the typedef level
+									readDeepNilability()
 								case *ast.IndexExpr, *ast.IndexListExpr:
-									// TODO - handle generics
+									// instantiation of a generic type (e.g., `type A B[int]`), whose
+									// deep nilability is read only if it instantiates a map, slice,
+									// array, pointer, or channel type
+									switch typeOf(typeVal).Underlying().(type) {
+									case *types.Map, *types.Slice, *types.Array, *types.Pointer, *types.Chan:
+										readDeepNilability()
+									}
 								case *ast.ParenExpr:
 									handleTypeVal(typeVal.X)
 								default:
@@ -625,6 +805,43 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 		}
 	}
 
+	// Read the annotations of the anonymous functions from their doc comments, which are stored for
+	// their fake function declarations later (see AddFuncLits).
+	funcLitAnnMap := make(map[*ast.FuncLit]funcLitAnn)
+	if conf.ExperimentalAnonymousFuncEnable {
+		for _, file := range files {
+			if !conf.IsFileInScope(file) {
+				continue
+			}
+			for funcLit, doc := range FuncLitDocs(pass.Fset, file) {
+				set := nilabilityFromCommentGroup(doc)
+				ann := funcLitAnn{
+					params:    accFromFieldList(set, funcLit.Type.Params, true, false),
+					results:   accFromFieldList(set, funcLit.Type.Results, false, false),
+					callbacks: make(map[CallbackAnnotationKey]Val),
+				}
+				readCallbackAnnotations(nil, pass.TypesInfo.TypeOf(funcLit).(*types.Signature), set, ann.callbacks)
+				funcLitAnnMap[funcLit] = ann
+			}
+		}
+	}
+
+	// Fields always set to non-nil values by the constructors of their struct types (and never
+	// reassigned to nil) are treated as non-nil, unless they are explicitly annotated otherwise.
+	var inScopeFiles []*ast.File
+	for _, file := range files {
+		if conf.IsFileInScope(file) {
+			inScopeFiles = append(inScopeFiles, file)
+		}
+	}
+	ctorInitFields := make(map[*types.Var]bool)
+	for fld := range constructorInitializedFields(pass, inScopeFiles) {
+		if val, ok := fieldAnnMap[fld]; ok && !val.IsNilableSet {
+			fieldAnnMap[fld] = val.makeNonNil(true)
+			ctorInitFields[fld] = true
+		}
+	}
+
 	// Parse inline annotations at call sites.
 	for _, file := range files {
 		if !conf.IsFileInScope(file) {
@@ -698,7 +915,53 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 		globalVarsAnnMap:        globalVarsAnnMap,
 		funcCallSiteParamAnnMap: funcCallSiteParamAnnMap,
 		funcCallSiteRetAnnMap:   funcCallSiteRetAnnMap,
+		callbackAnnMap:          callbackAnnMap,
+		ctorInitFields:          ctorInitFields,
+		funcLitAnnMap:           funcLitAnnMap,
+	}
+}
+
+// FuncLitDocs returns the doc comments of the anonymous functions (function literals) in the file,
+// which carry their annotations and contracts (e.g., the comment right above
+// `f := func(p *int) {...}`). The doc comment of an anonymous function is the comment group that
+// ends on the line right above it and starts its own line, i.e., the trailing comment of the code
+// on the line above is not considered.
+func FuncLitDocs(fset *token.FileSet, file *ast.File) map[*ast.FuncLit]*ast.CommentGroup {
+	// codeStarts maps each line to the first position on it where a (non-comment) node starts or
+	// ends, i.e., where the code on the line starts.
+	codeStarts := make(map[int]token.Pos)
+	var funcLits []*ast.FuncLit
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case nil, *ast.CommentGroup, *ast.Comment:
+			return false
+		case *ast.FuncLit:
+			funcLits = append(funcLits, node)
+		}
+		for _, pos := range [...]token.Pos{node.Pos(), node.End()} {
+			line := fset.Position(pos).Line
+			if start, ok := codeStarts[line]; !ok || pos < start {
+				codeStarts[line] = pos
+			}
+		}
+		return true
+	})
+
+	commentsByEndLine := make(map[int]*ast.CommentGroup, len(file.Comments))
+	for _, cg := range file.Comments {
+		if start, ok := codeStarts[fset.Position(cg.Pos()).Line]; ok && start < cg.Pos() {
+			continue
+		}
+		commentsByEndLine[fset.Position(cg.End()).Line] = cg
+	}
+
+	docs := make(map[*ast.FuncLit]*ast.CommentGroup)
+	for _, funcLit := range funcLits {
+		if cg, ok := commentsByEndLine[fset.Position(funcLit.Pos()).Line-1]; ok {
+			docs[funcLit] = cg
+		}
 	}
+	return docs
 }
 
 func getLineFromPos(pos token.Pos, pass *analysis.Pass) int {
@@ -0,0 +1,102 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"go/ast"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDocNilabilitySet(t *testing.T) {
+	t.Parallel()
+
+	nilable, nonnil := EmptyVal.makeNilable(true), EmptyVal.makeNonNil(true)
+	tests := []struct {
+		name string
+		text string
+		want nilabilitySet
+	}{
+		{name: "nilable", text: "// nilable(p, result 0)", want: nilabilitySet{"p": nilable, "result 0": nilable}},
+		{name: "no space", text: "//nonnil(p)", want: nilabilitySet{"p": nonnil}},
+		{name: "mixed", text: "// nilable(p) nonnil(q)", want: nilabilitySet{"p": nilable, "q": nonnil}},
+		{name: "always-nonnil", text: "// always-nonnil(result 0)", want: nilabilitySet{}},
+		{name: "error-nonnil", text: "// nilable(p) error-nonnil(result 0)", want: nilabilitySet{"p": nilable}},
+		{name: "prefixed keyword", text: "// notnilable(p)", want: nilabilitySet{}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			doc := &ast.CommentGroup{List: []*ast.Comment{{Text: tt.text}}}
+			require.Equal(t, tt.want, nilabilityFromCommentGroup(doc))
+		})
+	}
+}
+
+// FuzzReadDocNilabilitySet checks that reading the nilability annotations (e.g., `nilable(p)`)
+// from arbitrary doc comments never panics, is deterministic, only produces well-formed names with
+// set nilabilities, and that the shallow annotations survive a round trip through FormatAnnotation.
+func FuzzReadDocNilabilitySet(f *testing.F) {
+	for _, text := range []string{
+		"// nilable(p, result 0)",
+		"// nonnil(*p, s[], <-c, fn.param 0, fn.result 1)",
+		"// nilable( a ,b ) nonnil(c)",
+		"// nilable(p) nonnil(p)",
+		"// always-nonnil(result 0) error-nonnil(result 1)",
+		"// nilable()",
+		"// nilable(result 99999999999999999999",
+		"/* nonnil(x)\nnilable(y) */",
+	} {
+		f.Add(text)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		doc := &ast.CommentGroup{List: []*ast.Comment{{Text: text}}}
+		set := nilabilityFromCommentGroup(doc)
+		require.Equal(t, set, nilabilityFromCommentGroup(doc))
+
+		var nilable, nonnil []string
+		for name, val := range set {
+			require.NotEmpty(t, name)
+			require.Equal(t, strings.TrimSpace(name), name)
+			require.True(t, val.IsNilableSet || val.IsDeepNilableSet, "unset value for %q", name)
+			if !val.IsNilableSet {
+				continue
+			}
+			if val.IsNilable {
+				nilable = append(nilable, name)
+			} else {
+				nonnil = append(nonnil, name)
+			}
+		}
+		sort.Strings(nilable)
+		sort.Strings(nonnil)
+
+		formatted := &ast.CommentGroup{List: []*ast.Comment{
+			{Text: FormatAnnotation(nilable, true /* isNilable */)},
+			{Text: FormatAnnotation(nonnil, false /* isNilable */)},
+		}}
+		roundTrip := nilabilityFromCommentGroup(formatted)
+		require.Len(t, roundTrip, len(nilable)+len(nonnil))
+		for name, val := range roundTrip {
+			require.True(t, val.IsNilableSet, "unset value for %q", name)
+			require.Equal(t, set[name].IsNilable, val.IsNilable, "mismatched nilability for %q", name)
+		}
+	})
+}
@@ -457,6 +457,64 @@ func (BlankVarReturnPrestring) String() string {
 	return "return via a blank variable `_`"
 }
 
+// UnhandledExpr is used in strict mode when a value flows from an expression whose nilability is not
+// modeled by NilAway (e.g., a type assertion), and is thus conservatively assumed to be nilable
+type UnhandledExpr struct {
+	*ProduceTriggerTautology
+}
+
+// equals returns true if the passed ProducingAnnotationTrigger is equal to this one
+func (u *UnhandledExpr) equals(other ProducingAnnotationTrigger) bool {
+	if other, ok := other.(*UnhandledExpr); ok {
+		return u.ProduceTriggerTautology.equals(other.ProduceTriggerTautology)
+	}
+	return false
+}
+
+// Prestring returns this Prestring as a Prestring
+func (*UnhandledExpr) Prestring() Prestring {
+	return UnhandledExprPrestring{}
+}
+
+// UnhandledExprPrestring is a Prestring storing the needed information to compactly encode a UnhandledExpr
+type UnhandledExprPrestring struct{}
+
+func (UnhandledExprPrestring) String() string {
+	return "value of unhandled expression (strict mode)"
+}
+
+// GlobalVarReadBeforeInit is when a value is determined to flow from a global variable that is
+// declared without a value and only assigned in an init() function, but is read during the
+// initialization of package-level variables (i.e., before any init() function runs)
+type GlobalVarReadBeforeInit struct {
+	*ProduceTriggerTautology
+	VarObj *types.Var
+}
+
+// equals returns true if the passed ProducingAnnotationTrigger is equal to this one
+func (g *GlobalVarReadBeforeInit) equals(other ProducingAnnotationTrigger) bool {
+	if other, ok := other.(*GlobalVarReadBeforeInit); ok {
+		return g.ProduceTriggerTautology.equals(other.ProduceTriggerTautology) && g.VarObj == other.VarObj
+	}
+	return false
+}
+
+// Prestring returns this Prestring as a Prestring
+func (g *GlobalVarReadBeforeInit) Prestring() Prestring {
+	return GlobalVarReadBeforeInitPrestring{
+		VarName: g.VarObj.Name(),
+	}
+}
+
+// GlobalVarReadBeforeInitPrestring is a Prestring storing the needed information to compactly encode a GlobalVarReadBeforeInit
+type GlobalVarReadBeforeInitPrestring struct {
+	VarName string
+}
+
+func (g GlobalVarReadBeforeInitPrestring) String() string {
+	return fmt.Sprintf("global variable `%s` read before it is assigned in init()", g.VarName)
+}
+
 // DuplicateParamProducer duplicates a given produce trigger, assuming the given produce trigger
 // is of FuncParam.
 func DuplicateParamProducer(t *ProduceTrigger, location token.Position) *ProduceTrigger {
@@ -898,6 +956,69 @@ func (i InterfaceParamReachesImplementationPrestring) String() string {
 	return ""
 }
 
+// CallbackParam is used when a value is determined to flow from a parameter of a function-typed
+// parameter (i.e., a callback) of a function, i.e., an argument that the function passes when
+// calling the callback
+type CallbackParam struct {
+	*TriggerIfNilable
+}
+
+// equals returns true if the passed ProducingAnnotationTrigger is equal to this one
+func (c *CallbackParam) equals(other ProducingAnnotationTrigger) bool {
+	if other, ok := other.(*CallbackParam); ok {
+		return c.TriggerIfNilable.equals(other.TriggerIfNilable)
+	}
+	return false
+}
+
+// Prestring returns this CallbackParam as a Prestring
+func (c *CallbackParam) Prestring() Prestring {
+	key := c.Ann.(*CallbackAnnotationKey)
+	return CallbackParamPrestring{key.Num, key.CallbackName(), key.FuncName()}
+}
+
+// CallbackParamPrestring is a Prestring storing the needed information to compactly encode a CallbackParam
+type CallbackParamPrestring struct {
+	ParamNum     int
+	CallbackName string
+	FuncName     string
+}
+
+func (c CallbackParamPrestring) String() string {
+	return fmt.Sprintf("param %d of %s", c.ParamNum, callbackDescription(c.CallbackName, c.FuncName))
+}
+
+// CallbackResult is used when a value is determined to flow from a result of a function-typed
+// parameter (i.e., a callback) of a function
+type CallbackResult struct {
+	*TriggerIfNilable
+}
+
+// equals returns true if the passed ProducingAnnotationTrigger is equal to this one
+func (c *CallbackResult) equals(other ProducingAnnotationTrigger) bool {
+	if other, ok := other.(*CallbackResult); ok {
+		return c.TriggerIfNilable.equals(other.TriggerIfNilable)
+	}
+	return false
+}
+
+// Prestring returns this CallbackResult as a Prestring
+func (c *CallbackResult) Prestring() Prestring {
+	key := c.Ann.(*CallbackAnnotationKey)
+	return CallbackResultPrestring{key.Num, key.CallbackName(), key.FuncName()}
+}
+
+// CallbackResultPrestring is a Prestring storing the needed information to compactly encode a CallbackResult
+type CallbackResultPrestring struct {
+	RetNum       int
+	CallbackName string
+	FuncName     string
+}
+
+func (c CallbackResultPrestring) String() string {
+	return fmt.Sprintf("result %d of %s", c.RetNum, callbackDescription(c.CallbackName, c.FuncName))
+}
+
 // GlobalVarRead is when a value is determined to flow from a read to a global variable
 type GlobalVarRead struct {
 	*TriggerIfNilable
@@ -50,6 +50,8 @@ func (s *ProducingAnnotationTriggerTestSuite) SetupTest() {
 		&UnassignedFld{ProduceTriggerTautology: &ProduceTriggerTautology{}},
 		&NoVarAssign{ProduceTriggerTautology: &ProduceTriggerTautology{}},
 		&BlankVarReturn{ProduceTriggerTautology: &ProduceTriggerTautology{}},
+		&UnhandledExpr{ProduceTriggerTautology: &ProduceTriggerTautology{}},
+		&GlobalVarReadBeforeInit{ProduceTriggerTautology: &ProduceTriggerTautology{}},
 		&FuncParam{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
 		&MethodRecv{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
 		&MethodRecvDeep{TriggerIfDeepNilable: &TriggerIfDeepNilable{Ann: mockedKey}},
@@ -63,6 +65,8 @@ func (s *ProducingAnnotationTriggerTestSuite) SetupTest() {
 		&MethodReturn{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
 		&MethodResultReachesInterface{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
 		&InterfaceParamReachesImplementation{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
+		&CallbackParam{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
+		&CallbackResult{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
 		&GlobalVarRead{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
 		&MapRead{TriggerIfDeepNilable: &TriggerIfDeepNilable{Ann: mockedKey}},
 		&ArrayRead{TriggerIfDeepNilable: &TriggerIfDeepNilable{Ann: mockedKey}},
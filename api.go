@@ -0,0 +1,229 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nilaway
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"go/token"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/driver"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Options configures a programmatic run of NilAway via Run.
+type Options struct {
+	// Dir is the directory in which to load the packages, empty means the current directory.
+	Dir string
+	// Env is the environment for loading the packages, nil means the current environment.
+	Env []string
+	// Tests indicates whether the test packages should be analyzed as well.
+	Tests bool
+	// Global enables the global inference pass over all analyzed packages, which additionally
+	// finds the conflicts spanning sibling packages (see package driver).
+	Global bool
+	// Annotations indicates whether the annotations inferred for the exported API of the analyzed
+	// packages should be collected in Report.Annotations.
+	Annotations bool
+	// Flags are the NilAway configurations keyed by the flag names (e.g., config.IncludePkgsFlag),
+	// which are the same as the flags of the standalone checker.
+	Flags map[string]string
+}
+
+// Report is the structured result of a run of NilAway.
+type Report struct {
+	// Findings are the potential nil panics found in the analyzed packages, sorted by positions.
+	Findings []Finding
+	// Annotations are the annotations inferred for the exported API of the analyzed packages, which
+	// are only collected if Options.Annotations is set.
+	Annotations []Annotation
+	// Stats are the statistics of the run.
+	Stats Stats
+}
+
+// Finding is a potential nil panic found by NilAway.
+type Finding struct {
+	// Package is the path of the package where the finding is reported.
+	Package string
+	// Position is the position where the finding is reported.
+	Position token.Position
+	// Category is the stable code of the class of the finding (e.g., "ptr-load"), which can be
+	// used in the category configurations (see config.DisableCategoriesFlag).
+	Category string
+	// Warning indicates whether the finding is downgraded to a warning (see
+	// config.DowngradeCategoriesFlag).
+	Warning bool
+	// Global indicates whether the finding is only reported by the global inference pass (see
+	// Options.Global).
+	Global bool
+	// Message is the complete message of the finding, as reported by the standalone checker.
+	Message string
+	// Flow is the nil flow from the nilable source to the dereference point.
+	Flow []FlowStep
+}
+
+// FlowStep is a step in the nil flow of a finding.
+type FlowStep struct {
+	// Position is the position of the step, which is invalid if it cannot be resolved.
+	Position token.Position
+	// Description describes the step, e.g., "literal `nil` returned from `foo()` in position 0".
+	Description string
+}
+
+// Annotation is an inferred annotation for a declaration in the exported API of a package.
+type Annotation struct {
+	// Package is the path of the package of the declaration.
+	Package string
+	// Position is the position of the declaration.
+	Position token.Position
+	// Message describes the declaration and the inferred annotations.
+	Message string
+	// Text is the annotation comment(s) to be inserted right before the declaration.
+	Text string
+}
+
+// Stats are the statistics of a run of NilAway.
+type Stats struct {
+	// Packages is the number of the analyzed packages (i.e., the ones matching the patterns).
+	Packages int
+	// Triggers is the total number of the full triggers collected from the analyzed packages.
+	Triggers int
+	// NilableSites, NonnilSites, and UndeterminedSites are the total numbers of the annotation
+	// sites in the analyzed packages that are inferred nilable, inferred nonnil, and left
+	// undetermined, respectively.
+	NilableSites, NonnilSites, UndeterminedSites int
+	// AnalyzedFunctions is the total number of the functions analyzed in the analyzed packages.
+	AnalyzedFunctions int
+	// SkippedFunctions is the total number of the functions skipped for being too large, and
+	// CancelledFunctions is the total number of the functions whose analyses were cancelled for
+	// exceeding the memory budget (see the "memory-budget-mb" flag). No findings are reported for
+	// either of them.
+	SkippedFunctions, CancelledFunctions int
+	// Findings and Warnings are the numbers of the findings reported as errors and as warnings.
+	Findings, Warnings int
+	// Duration is the wall time of the run.
+	Duration time.Duration
+}
+
+// _runMu serializes the runs since NilAway is configured by the (global) flags of config.Analyzer.
+var _runMu sync.Mutex
+
+// Run loads the packages matching the patterns (e.g., "./..."), analyzes them (along with their
+// dependencies for cross-package inference) in-process, and returns the structured report. This
+// is the stable API for embedding NilAway in other tools, where the configurations are passed via
+// Options.Flags instead of the command line. Concurrent calls are serialized.
+func Run(ctx context.Context, patterns []string, opts Options) (*Report, error) {
+	_runMu.Lock()
+	defer _runMu.Unlock()
+
+	// Apply the flags and restore the original values afterward, such that the runs do not affect
+	// each other (or the analyzers running in the same process).
+	flags := make(map[string]string, len(opts.Flags)+1)
+	for name, value := range opts.Flags {
+		flags[name] = value
+	}
+	if opts.Annotations {
+		flags[config.CollectAnnotationsFlag] = "true"
+	}
+	for name, value := range flags {
+		f := config.Analyzer.Flags.Lookup(name)
+		if f == nil {
+			return nil, fmt.Errorf("unknown flag %q", name)
+		}
+		original := f.Value.String()
+		if err := f.Value.Set(value); err != nil {
+			return nil, fmt.Errorf("set flag %q to %q: %w", name, value, err)
+		}
+		defer func() {
+			// The original value has been successfully set before, so this never fails.
+			_ = f.Value.Set(original)
+		}()
+	}
+
+	start := time.Now()
+	res, err := driver.Run(ctx, patterns, driver.Options{Dir: opts.Dir, Env: opts.Env, Tests: opts.Tests, Global: opts.Global})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	addFinding := func(d driver.Diagnostic, global bool) {
+		f := Finding{
+			Package:  d.Package.PkgPath,
+			Position: res.Fset.Position(d.Pos),
+			Category: d.Category,
+			Warning:  res.Config.IsCategoryDowngraded(d.Category, d.Package.Types),
+			Global:   global,
+			Message:  d.Message,
+		}
+		for _, r := range d.Related {
+			f.Flow = append(f.Flow, FlowStep{Position: res.Fset.Position(r.Pos), Description: r.Message})
+		}
+		if f.Warning {
+			report.Stats.Warnings++
+		} else {
+			report.Stats.Findings++
+		}
+		report.Findings = append(report.Findings, f)
+	}
+	for _, d := range res.Diagnostics {
+		addFinding(d, false /* global */)
+	}
+	for _, d := range res.GlobalDiagnostics {
+		addFinding(d, true /* global */)
+	}
+	slices.SortStableFunc(report.Findings, func(a, b Finding) int {
+		if n := cmp.Compare(a.Position.Filename, b.Position.Filename); n != 0 {
+			return n
+		}
+		return cmp.Compare(a.Position.Offset, b.Position.Offset)
+	})
+
+	for _, d := range res.Annotations {
+		report.Annotations = append(report.Annotations, Annotation{
+			Package:  d.Package.PkgPath,
+			Position: res.Fset.Position(d.Pos),
+			Message:  d.Message,
+			Text:     suggestedText(d.Diagnostic),
+		})
+	}
+
+	for _, s := range res.Stats {
+		report.Stats.Packages++
+		report.Stats.Triggers += s.Triggers
+		report.Stats.NilableSites += s.NilableSites
+		report.Stats.NonnilSites += s.NonnilSites
+		report.Stats.UndeterminedSites += s.UndeterminedSites
+		report.Stats.AnalyzedFunctions += s.Functions.Analyzed
+		report.Stats.SkippedFunctions += s.Functions.SkippedTooLarge
+		report.Stats.CancelledFunctions += s.Functions.CancelledOverBudget
+	}
+	report.Stats.Duration = time.Since(start)
+
+	return report, nil
+}
+
+// suggestedText returns the text inserted by the suggested fix of the annotation diagnostic.
+func suggestedText(d analysis.Diagnostic) string {
+	if len(d.SuggestedFixes) == 0 || len(d.SuggestedFixes[0].TextEdits) == 0 {
+		return ""
+	}
+	return string(d.SuggestedFixes[0].TextEdits[0].NewText)
+}
@@ -0,0 +1,123 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nilaway
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/nilaway/config"
+)
+
+// TestRun is not parallel since the flags of config.Analyzer are shared with the other tests.
+func TestRun(t *testing.T) { //nolint:paralleltest
+	gopath, err := filepath.Abs("testdata")
+	require.NoError(t, err)
+	env := append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off", "GOPROXY=off")
+
+	original := config.Analyzer.Flags.Lookup(config.DowngradeCategoriesFlag).Value.String()
+	report, err := Run(context.Background(), []string{"go.uber.org/wholeprogram/..."}, Options{
+		Env:         env,
+		Global:      true,
+		Annotations: true,
+		Flags:       map[string]string{config.DowngradeCategoriesFlag: "ptr-load:go.uber.org/wholeprogram/reader"},
+	})
+	require.NoError(t, err)
+
+	// The original flags should be restored after the run.
+	require.Equal(t, original, config.Analyzer.Flags.Lookup(config.DowngradeCategoriesFlag).Value.String())
+	require.Equal(t, "false", config.Analyzer.Flags.Lookup(config.CollectAnnotationsFlag).Value.String())
+
+	require.Len(t, report.Findings, 2)
+	global := report.Findings[0]
+	require.True(t, global.Global)
+	require.True(t, global.Warning)
+	require.Equal(t, "go.uber.org/wholeprogram/reader", global.Package)
+	require.Equal(t, "ptr-load", global.Category)
+	require.Contains(t, global.Message, "(downgraded to warning)")
+	require.Len(t, global.Flow, 2)
+	require.Equal(t, "writer.go", filepath.Base(global.Flow[0].Position.Filename))
+	require.True(t, strings.HasPrefix(global.Flow[0].Description, "literal `nil`"), global.Flow[0].Description)
+	require.Equal(t, global.Position, global.Flow[1].Position)
+	require.False(t, report.Findings[1].Global)
+
+	require.Len(t, report.Annotations, 1)
+	require.Equal(t, "go.uber.org/wholeprogram/writer", report.Annotations[0].Package)
+	require.Equal(t, "// nilable(result 0)\n", report.Annotations[0].Text)
+
+	require.Equal(t, 3, report.Stats.Packages)
+	require.Equal(t, 0, report.Stats.Findings)
+	require.Equal(t, 2, report.Stats.Warnings)
+	require.Positive(t, report.Stats.Triggers)
+	require.Positive(t, report.Stats.NilableSites)
+	require.Positive(t, report.Stats.AnalyzedFunctions)
+	require.Zero(t, report.Stats.CancelledFunctions)
+}
+
+func TestRun_NoAnnotations(t *testing.T) { //nolint:paralleltest
+	gopath, err := filepath.Abs("testdata")
+	require.NoError(t, err)
+	env := append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off", "GOPROXY=off")
+
+	// The annotations are only collected if requested.
+	report, err := Run(context.Background(), []string{"go.uber.org/wholeprogram/..."}, Options{Env: env})
+	require.NoError(t, err)
+	require.Empty(t, report.Annotations)
+	require.Positive(t, report.Stats.NilableSites)
+}
+
+func TestRun_OverBudget(t *testing.T) { //nolint:paralleltest
+	gopath, err := filepath.Abs("testdata")
+	require.NoError(t, err)
+	env := append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off", "GOPROXY=off")
+
+	// The ballast keeps the heap beyond 1 MiB, so the analysis of every function is cancelled right
+	// away when the functions are analyzed one at a time, which must be reported instead of
+	// silently dropped.
+	ballast := make([]byte, 2<<20)
+	defer runtime.KeepAlive(ballast)
+	report, err := Run(context.Background(), []string{"go.uber.org/wholeprogram/..."}, Options{
+		Env: env,
+		Flags: map[string]string{
+			config.MemoryBudgetFlag:    "1",
+			config.FunctionWorkersFlag: "1",
+		},
+	})
+	require.NoError(t, err)
+	require.Positive(t, report.Stats.CancelledFunctions)
+	require.Zero(t, report.Stats.AnalyzedFunctions)
+
+	var incomplete []Finding
+	for _, f := range report.Findings {
+		if strings.HasPrefix(f.Message, "INCOMPLETE ANALYSIS") {
+			incomplete = append(incomplete, f)
+		}
+	}
+	require.NotEmpty(t, incomplete)
+	for _, f := range incomplete {
+		require.Contains(t, f.Message, config.MemoryBudgetFlag)
+		require.True(t, f.Position.IsValid(), f.Position)
+	}
+}
+
+func TestRun_UnknownFlag(t *testing.T) { //nolint:paralleltest
+	_, err := Run(context.Background(), []string{"./..."}, Options{Flags: map[string]string{"unknown": "true"}})
+	require.ErrorContains(t, err, "unknown")
+}
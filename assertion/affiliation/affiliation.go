@@ -18,6 +18,7 @@ package affiliation
 
 import (
 	"go/ast"
+	"go/token"
 	"go/types"
 	"strings"
 
@@ -102,136 +103,171 @@ func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstre
 			if !ok {
 				continue
 			}
+			sig, ok := pass.TypesInfo.ObjectOf(f.Name).Type().(*types.Signature)
+			if !ok {
+				continue
+			}
+			a.inspectCastingSites(pass, f, sig, appendTypeToTypeTriggers)
+		}
+	}
+}
 
-			ast.Inspect(f, func(n ast.Node) bool {
-				switch node := n.(type) {
-				case *ast.AssignStmt:
-					// special case of n-to-1 assignment from a function with multiple returns: e.g., i1, i2 = foo(), where foo() return s1, s2
-					// note that other n-to-1 assignments (e.g. v, ok := m[k]) are handled by the loop below, since only the first LHS element is
-					// being directly assigned to in a way we care about
-					if len(node.Rhs) == 1 && len(node.Lhs) > 1 {
-						if rhsSig, ok := pass.TypesInfo.TypeOf(node.Rhs[0]).(*types.Tuple); ok && rhsSig.Len() == len(node.Lhs) {
-							for i := range node.Lhs {
-								lhsType := pass.TypesInfo.TypeOf(node.Lhs[i])
-								rhsType := rhsSig.At(i).Type()
-								appendTypeToTypeTriggers(lhsType, rhsType)
-							}
-							return true
-						}
-					}
-					// e.g., var i I, var s *S, i = s, or more generally, i1, i2, i3 = s1, s2, s3
-					for i := 0; i < len(node.Lhs) && i < len(node.Rhs); i++ {
+// inspectCastingSites inspects the sites of casts in the AST node `root`, which is (or is nested in)
+// the body of a function (or anonymous function) with signature `sig`, and calls
+// appendTypeToTypeTriggers on the types of each cast.
+func (a *Affiliation) inspectCastingSites(pass *analysis.Pass, root ast.Node, sig *types.Signature, appendTypeToTypeTriggers func(lhsType, rhsType types.Type)) {
+	ast.Inspect(root, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			// special case of n-to-1 assignment from a function with multiple returns: e.g., i1, i2 = foo(), where foo() return s1, s2
+			// note that other n-to-1 assignments (e.g. v, ok := m[k]) are handled by the loop below, since only the first LHS element is
+			// being directly assigned to in a way we care about
+			if len(node.Rhs) == 1 && len(node.Lhs) > 1 {
+				if rhsSig, ok := pass.TypesInfo.TypeOf(node.Rhs[0]).(*types.Tuple); ok && rhsSig.Len() == len(node.Lhs) {
+					for i := range node.Lhs {
 						lhsType := pass.TypesInfo.TypeOf(node.Lhs[i])
-						rhsType := pass.TypesInfo.TypeOf(node.Rhs[i])
-						appendTypeToTypeTriggers(lhsType, rhsType)
-					}
-				case *ast.ValueSpec:
-					// e.g., var i I = &S{}
-					for i := 0; i < len(node.Values); i++ {
-						lhsType := pass.TypesInfo.TypeOf(node.Type)
-						rhsType := pass.TypesInfo.TypeOf(node.Values[i])
+						rhsType := rhsSig.At(i).Type()
 						appendTypeToTypeTriggers(lhsType, rhsType)
 					}
-				case *ast.CallExpr:
-					// e.g., func foo(i I), foo(&S{})
-					if ident := util.FuncIdentFromCallExpr(node); ident != nil {
-						if declObj := pass.TypesInfo.Uses[ident]; declObj != nil {
-							if fdecl, ok := declObj.(*types.Func); ok {
-								fsig := fdecl.Type().(*types.Signature)
-								for i := 0; i < fsig.Params().Len() && i < len(node.Args); i++ {
-									lhsType := fsig.Params().At(i).Type()          // receiver param of method declaration
-									rhsType := pass.TypesInfo.TypeOf(node.Args[i]) // caller param
-									appendTypeToTypeTriggers(lhsType, rhsType)
-								}
+					return true
+				}
+			}
+			// e.g., var i I, var s *S, i = s, or more generally, i1, i2, i3 = s1, s2, s3. Note that
+			// this also covers the writes into maps and slices, e.g., m[k] = s, where the type of
+			// the lhs is the value type of the map.
+			for i := 0; i < len(node.Lhs) && i < len(node.Rhs); i++ {
+				lhsType := pass.TypesInfo.TypeOf(node.Lhs[i])
+				rhsType := pass.TypesInfo.TypeOf(node.Rhs[i])
+				appendTypeToTypeTriggers(lhsType, rhsType)
+			}
+		case *ast.ValueSpec:
+			// e.g., var i I = &S{}
+			for i := 0; i < len(node.Values); i++ {
+				lhsType := pass.TypesInfo.TypeOf(node.Type)
+				rhsType := pass.TypesInfo.TypeOf(node.Values[i])
+				appendTypeToTypeTriggers(lhsType, rhsType)
+			}
+		case *ast.SendStmt:
+			// channel is declared of type interface, and a struct is sent to it
+			// e.g., ch := make(chan I), ch <- &S{}
+			if t := pass.TypesInfo.TypeOf(node.Chan); t != nil {
+				if chanType, ok := t.Underlying().(*types.Chan); ok {
+					appendTypeToTypeTriggers(chanType.Elem(), pass.TypesInfo.TypeOf(node.Value))
+				}
+			}
+		case *ast.CallExpr:
+			// e.g., func foo(i I), foo(&S{})
+			if ident := util.FuncIdentFromCallExpr(node); ident != nil {
+				if declObj := pass.TypesInfo.Uses[ident]; declObj != nil {
+					if fdecl, ok := declObj.(*types.Func); ok {
+						fsig := fdecl.Type().(*types.Signature)
+						for i := 0; i < len(node.Args); i++ {
+							var lhsType types.Type // receiver param of method declaration
+							switch {
+							case fsig.Variadic() && i >= fsig.Params().Len()-1 && node.Ellipsis == token.NoPos:
+								// e.g., func foo(is ...I), foo(&S1{}, &S2{})
+								lhsType = fsig.Params().At(fsig.Params().Len() - 1).Type().(*types.Slice).Elem()
+							case i < fsig.Params().Len():
+								lhsType = fsig.Params().At(i).Type()
 							}
-						}
-					}
-
-					// slice is declared to be of interface type, and append function is used to add struct
-					if sliceType, ok := util.IsSliceAppendCall(node, pass); ok {
-						for i := 1; i < len(node.Args); i++ {
-							lhsType := sliceType.Elem()
-							rhsType := pass.TypesInfo.TypeOf(node.Args[i])
+							rhsType := pass.TypesInfo.TypeOf(node.Args[i]) // caller param
 							appendTypeToTypeTriggers(lhsType, rhsType)
 						}
 					}
+				}
+			}
 
-				case *ast.TypeAssertExpr:
-					// e.g., v, ok := i.(*S)
-					lhsType := pass.TypesInfo.TypeOf(node.X)
-					rhsType := pass.TypesInfo.TypeOf(node.Type)
+			// slice is declared to be of interface type, and append function is used to add struct
+			if sliceType, ok := util.IsSliceAppendCall(node, pass); ok && node.Ellipsis == token.NoPos {
+				for i := 1; i < len(node.Args); i++ {
+					lhsType := sliceType.Elem()
+					rhsType := pass.TypesInfo.TypeOf(node.Args[i])
 					appendTypeToTypeTriggers(lhsType, rhsType)
+				}
+			}
 
-				case *ast.ReturnStmt:
-					// function signature states interface return, but the actual return is a struct
-					// e.g., m(x *A) I { return x }
-					var results = f.Type.Results
-					if results != nil {
-						funcSigResultsList := results.List
-						for i := range node.Results {
-							if i < len(funcSigResultsList) {
-								lhsType := pass.TypesInfo.TypeOf(funcSigResultsList[i].Type)
-								rhsType := pass.TypesInfo.TypeOf(node.Results[i])
-								appendTypeToTypeTriggers(lhsType, rhsType)
-							}
-						}
-					}
+		case *ast.TypeAssertExpr:
+			// e.g., v, ok := i.(*S)
+			lhsType := pass.TypesInfo.TypeOf(node.X)
+			rhsType := pass.TypesInfo.TypeOf(node.Type)
+			appendTypeToTypeTriggers(lhsType, rhsType)
 
-				case *ast.CompositeLit:
-					switch nodeType := node.Type.(type) {
-					case *ast.ArrayType:
-						// A slice (or array) declared of type interface, and initialized with a struct
-						// e.g., _ = []I{&S{}}
-						// TODO: currently, nested composite literal for ArrayType is not supported (e.g., _ = [][]I{{&A1{}}}).
-						//  Tracked in issue #46.
-						lhsType := pass.TypesInfo.TypeOf(nodeType.Elt)
-						for _, elt := range node.Elts {
-							appendTypeToTypeTriggers(lhsType, pass.TypesInfo.TypeOf(elt))
-						}
-					case *ast.MapType:
-						// Key, value, or both of a map declared of type interface, and initialized with a struct
-						// e.g., _ = map[int]I{0: &S{}}
-						keyType := pass.TypesInfo.TypeOf(nodeType.Key)
-						valueType := pass.TypesInfo.TypeOf(nodeType.Value)
-						for _, elt := range node.Elts {
-							if kv, ok := elt.(*ast.KeyValueExpr); ok {
-								appendTypeToTypeTriggers(keyType, pass.TypesInfo.TypeOf(kv.Key))
-								appendTypeToTypeTriggers(valueType, pass.TypesInfo.TypeOf(kv.Value))
-							}
-						}
-					case *ast.Ident:
-						// A struct field (embedded or explicit) declared of type interface, and initialized with a struct
-						// e.g., var i I = S{t:&T{}}, where `type S struct { t J }`. (Here I and J are interfaces,
-						// and S and T are structs implementing them, respectively.)
-						// Similarly, embedding is also supported. E.g., var i I = &S{&T{}}, where `type S struct { J }`.
-						for i, elt := range node.Elts {
-							var lhsType, rhsType types.Type
-							if kv, ok := elt.(*ast.KeyValueExpr); ok {
-								// In this case the initialization is key-value based. E.g. s = &S{t: &T{}}
-								lhsType = pass.TypesInfo.TypeOf(kv.Key)
-								rhsType = pass.TypesInfo.TypeOf(kv.Value)
-							} else {
-								// In this case the initialization is serial. E.g. s = &S{&T{}}
-								if sObj := util.TypeAsDeeplyStruct(pass.TypesInfo.TypeOf(node)); sObj != nil {
-									lhsType = sObj.Field(i).Type()
-									rhsType = pass.TypesInfo.TypeOf(elt)
-								}
-							}
-							if lhsType != nil && rhsType != nil {
-								appendTypeToTypeTriggers(lhsType, rhsType)
-							}
-						}
-					}
-				case *ast.FuncLit:
-					// TODO: Nilability analysis support for anonymous functions is currently not
-					//       implemented (tracked in issue #52), so here we completely skip
-					//       the affiliation analysis for them.
-					return false
+		case *ast.ReturnStmt:
+			// function signature states interface return, but the actual return is a struct
+			// e.g., m(x *A) I { return x }
+			if len(node.Results) == sig.Results().Len() {
+				for i := range node.Results {
+					lhsType := sig.Results().At(i).Type()
+					rhsType := pass.TypesInfo.TypeOf(node.Results[i])
+					appendTypeToTypeTriggers(lhsType, rhsType)
 				}
+			}
+
+		case *ast.CompositeLit:
+			// Here we switch on the type of the composite literal instead of its syntax, since the
+			// type is elided in the nested composite literals, e.g., _ = [][]I{{&S{}}}, where the
+			// inner composite literal `{&S{}}` is of type []I.
+			t := pass.TypesInfo.TypeOf(node)
+			if t == nil {
 				return true
-			})
+			}
+			switch litType := t.Underlying().(type) {
+			case *types.Slice, *types.Array:
+				// A slice (or array) declared of type interface, and initialized with a struct
+				// e.g., _ = []I{&S{}}
+				var elemType types.Type
+				if sliceType, ok := litType.(*types.Slice); ok {
+					elemType = sliceType.Elem()
+				} else {
+					elemType = litType.(*types.Array).Elem()
+				}
+				for _, elt := range node.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						// e.g., _ = []I{2: &S{}}
+						elt = kv.Value
+					}
+					appendTypeToTypeTriggers(elemType, pass.TypesInfo.TypeOf(elt))
+				}
+			case *types.Map:
+				// Key, value, or both of a map declared of type interface, and initialized with a struct
+				// e.g., _ = map[int]I{0: &S{}}
+				for _, elt := range node.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						appendTypeToTypeTriggers(litType.Key(), pass.TypesInfo.TypeOf(kv.Key))
+						appendTypeToTypeTriggers(litType.Elem(), pass.TypesInfo.TypeOf(kv.Value))
+					}
+				}
+			case *types.Struct:
+				// A struct field (embedded or explicit) declared of type interface, and initialized with a struct
+				// e.g., var i I = S{t:&T{}}, where `type S struct { t J }`. (Here I and J are interfaces,
+				// and S and T are structs implementing them, respectively.)
+				// Similarly, embedding is also supported. E.g., var i I = &S{&T{}}, where `type S struct { J }`.
+				for i, elt := range node.Elts {
+					var lhsType, rhsType types.Type
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						// In this case the initialization is key-value based. E.g. s = &S{t: &T{}}
+						lhsType = pass.TypesInfo.TypeOf(kv.Key)
+						rhsType = pass.TypesInfo.TypeOf(kv.Value)
+					} else if i < litType.NumFields() {
+						// In this case the initialization is serial. E.g. s = &S{&T{}}
+						lhsType = litType.Field(i).Type()
+						rhsType = pass.TypesInfo.TypeOf(elt)
+					}
+					if lhsType != nil && rhsType != nil {
+						appendTypeToTypeTriggers(lhsType, rhsType)
+					}
+				}
+			}
+		case *ast.FuncLit:
+			// The returns in the anonymous function are checked against its own signature, so we
+			// inspect its body separately.
+			if litSig, ok := pass.TypesInfo.TypeOf(node).(*types.Signature); ok {
+				a.inspectCastingSites(pass, node.Body, litSig, appendTypeToTypeTriggers)
+			}
+			return false
 		}
-	}
+		return true
+	})
 }
 
 // computeTriggersForTypes finds corresponding concrete implementation and their declared methods and populates them in a map
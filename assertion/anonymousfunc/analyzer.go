@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"strconv"
 
+	"go.uber.org/nilaway/annotation"
 	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/util/analysishelper"
 	"golang.org/x/tools/go/analysis"
@@ -52,6 +53,10 @@ type FuncLitInfo struct {
 	// ClosureVars stores a slice of assigned / accessed variables from closure within each
 	// function literal in the order of their appearances.
 	ClosureVars []*VarInfo
+	// Doc is the doc comment of the func lit node (e.g., the comment above `f := func(...) {...}`,
+	// see annotation.FuncLitDocs), which serves as the doc comment of the fake func decl node to
+	// carry annotations and contracts. It is nil if there is no such comment group.
+	Doc *ast.CommentGroup
 }
 
 // VarInfo keeps the information about a variable (*ast.Ident) and its associated object type
@@ -93,6 +98,8 @@ func run(pass *analysis.Pass) (map[*ast.FuncLit]*FuncLitInfo, error) {
 			return true
 		})
 
+		docs := annotation.FuncLitDocs(pass.Fset, file)
+
 		for funcLit, vars := range closureMap {
 			fakeDecl, fakeType := createFakeFuncDecl(pass, funcLit, vars)
 
@@ -100,6 +107,7 @@ func run(pass *analysis.Pass) (map[*ast.FuncLit]*FuncLitInfo, error) {
 				FakeFuncDecl: fakeDecl,
 				FakeFuncObj:  fakeType,
 				ClosureVars:  vars,
+				Doc:          docs[funcLit],
 			}
 		}
 	}
@@ -20,8 +20,10 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 	"reflect"
+	"runtime"
 	"runtime/debug"
 	"strings"
 	"sync"
@@ -30,6 +32,8 @@ import (
 	"go.uber.org/nilaway/assertion/anonymousfunc"
 	"go.uber.org/nilaway/assertion/function/assertiontree"
 	"go.uber.org/nilaway/assertion/function/functioncontracts"
+	"go.uber.org/nilaway/assertion/function/nonnilresults"
+	"go.uber.org/nilaway/assertion/global"
 	"go.uber.org/nilaway/assertion/structfield"
 	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/util"
@@ -49,13 +53,15 @@ var Analyzer = &analysis.Analyzer{
 	Name:       "nilaway_function_analyzer",
 	Doc:        _doc,
 	Run:        analysishelper.WrapRun(run),
-	ResultType: reflect.TypeOf((*analysishelper.Result[[]annotation.FullTrigger])(nil)),
+	ResultType: reflect.TypeOf((*analysishelper.Result[*Result])(nil)),
 	Requires: []*analysis.Analyzer{
 		config.Analyzer,
+		annotation.Analyzer,
 		ctrlflow.Analyzer,
 		structfield.Analyzer,
 		anonymousfunc.Analyzer,
 		functioncontracts.Analyzer,
+		nonnilresults.Analyzer,
 	},
 }
 
@@ -65,31 +71,60 @@ var Analyzer = &analysis.Analyzer{
 // TODO: test how often (if ever) this is hit
 const _maxFuncSizeInBytes = 10000
 
+// Result is the result of the function analyzer.
+type Result struct {
+	// Triggers are the full triggers created for the functions in the package.
+	Triggers []annotation.FullTrigger
+	// Stats are the statistics of the analyses of the functions.
+	Stats Stats
+	// Cancelled are the positions of the functions whose analyses were cancelled for exceeding
+	// the memory budget, in the order of their declarations.
+	Cancelled []token.Pos
+}
+
+// Stats are the statistics of the analyses of the functions in a package.
+type Stats struct {
+	// Analyzed is the number of functions analyzed.
+	Analyzed int
+	// SkippedTooLarge is the number of functions skipped for being too large.
+	SkippedTooLarge int
+	// CancelledOverBudget is the number of functions whose analyses were cancelled since the heap
+	// size exceeded the memory budget (see config.Config.MemoryBudget). Their triggers are dropped
+	// as if the functions were skipped.
+	CancelledOverBudget int
+}
+
+// functionJob is a function to be analyzed.
+type functionJob struct {
+	funcDecl *ast.FuncDecl
+	// funcLit is the function literal if the function is anonymous, nil otherwise.
+	funcLit *ast.FuncLit
+	graph   *cfg.CFG
+}
+
 // functionResult is the struct that stores the results for analyzing a function declaration.
 type functionResult struct {
 	// triggers is the slice of triggers generated from analyzing a particular function.
 	triggers []annotation.FullTrigger
 	// err stores any error occurred during the analysis.
 	err error
-	// index is the index of the function declaration in the package. This is particularly
-	// important since currently we have hidden coupling in NilAway that requires the generated
-	// triggers be placed in order of their declarations. Here, the index will ensure that we can
-	// place the triggers in their original order, even though the analyses of function
-	// declarations can be parallelized.
-	// TODO: remove this.
+	// index is the index of the function declaration in the package. The inference results do
+	// not depend on the order of the triggers, but the index ensures that we can still place the
+	// triggers in their original order (for deterministic outputs), even though the analyses of
+	// function declarations can be parallelized.
 	index int
 	// funcDecl is the function declaration itself.
 	funcDecl *ast.FuncDecl
 }
 
-func run(pass *analysis.Pass) ([]annotation.FullTrigger, error) {
+func run(pass *analysis.Pass) (*Result, error) {
 	conf := pass.ResultOf[config.Analyzer].(*config.Config)
 	if !conf.IsPkgInScope(pass.Pkg) {
 		return nil, nil
 	}
 
 	// Construct experimental features. By default, enable all features on NilAway itself.
-	functionConfig := assertiontree.FunctionConfig{}
+	functionConfig := assertiontree.FunctionConfig{StrictMode: conf.IsPkgStrict(pass.Pkg)}
 	if strings.HasPrefix(pass.Pkg.Path(), config.NilAwayPkgPathPrefix) { //nolint:revive
 		// TODO: enable struct initialization flag (tracked in Issue #23).
 		// TODO: enable anonymous function flag.
@@ -101,11 +136,16 @@ func run(pass *analysis.Pass) ([]annotation.FullTrigger, error) {
 	ctrlflowResult := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
 	anonymousFuncResult := pass.ResultOf[anonymousfunc.Analyzer].(*analysishelper.Result[map[*ast.FuncLit]*anonymousfunc.FuncLitInfo])
 	contractsResult := pass.ResultOf[functioncontracts.Analyzer].(*analysishelper.Result[functioncontracts.Map])
-	if err := errors.Join(anonymousFuncResult.Err, contractsResult.Err); err != nil {
+	nonNilResultsResult := pass.ResultOf[nonnilresults.Analyzer].(*analysishelper.Result[nonnilresults.Map])
+	annotationsResult := pass.ResultOf[annotation.Analyzer].(*analysishelper.Result[*annotation.ObservedMap])
+	if err := errors.Join(anonymousFuncResult.Err, contractsResult.Err, nonNilResultsResult.Err, annotationsResult.Err); err != nil {
 		return nil, err
 	}
 
-	funcLitMap, funcContracts := anonymousFuncResult.Res, contractsResult.Res
+	funcLitMap, funcContracts, nonNilResults := anonymousFuncResult.Res, contractsResult.Res, nonNilResultsResult.Res
+	// The fields initialized by the constructors in the package are shared for all function
+	// contexts.
+	ctorInitFields := annotationsResult.Res.ConstructorInitializedFields()
 
 	// Create a fake ident map for the fake func decl nodes to be shared for all function contexts.
 	pkgFakeIdentMap := make(map[*ast.Ident]types.Object)
@@ -113,14 +153,24 @@ func run(pass *analysis.Pass) ([]annotation.FullTrigger, error) {
 		pkgFakeIdentMap[info.FakeFuncDecl.Name] = info.FakeFuncObj
 	}
 
-	// Set up variables for synchronization and communication.
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	var wg sync.WaitGroup
-	funcChan := make(chan functionResult)
-	// We use this to keep track of the index of the function declaration we are analyzing.
-	// TODO: remove this once  is done.
-	var funcIndex int
+	// Collect the interface-typed variables compared against nil (for checking typed nils), which
+	// are shared for all function contexts.
+	var files []*ast.File
+	for _, file := range pass.Files {
+		if conf.IsFileInScope(file) {
+			files = append(files, file)
+		}
+	}
+	nilComparedVars := assertiontree.CollectNilComparedVars(pass, files)
+	// The global variables assigned in init() functions that may still be zero-valued at the
+	// entries of the functions.
+	unassignedGlobals := global.UnassignedGlobals(pass, files)
+
+	// Collect the functions to analyze.
+	var (
+		jobs  []functionJob
+		stats Stats
+	)
 	for _, file := range pass.Files {
 		// Skip if a file is marked to be ignored, or it is not in scope of our analysis.
 		if !conf.IsFileInScope(file) {
@@ -177,16 +227,63 @@ func run(pass *analysis.Pass) ([]annotation.FullTrigger, error) {
 			// Skip if the function is too large.
 			funcSizeInBytes := int(funcDecl.Body.Rbrace - funcDecl.Body.Lbrace)
 			if funcSizeInBytes > _maxFuncSizeInBytes {
+				stats.SkippedTooLarge++
 				continue
 			}
+			jobs = append(jobs, functionJob{funcDecl: funcDecl, funcLit: funcLit, graph: graph})
+		}
+	}
 
-			// Now, analyze the function declarations concurrently.
-			wg.Add(1)
-			funcContext := assertiontree.NewFunctionContext(
-				pass, funcDecl, funcLit, functionConfig, funcLitMap, pkgFakeIdentMap, funcContracts)
-			go analyzeFunc(ctx, pass, funcDecl, funcContext, graph, funcIndex, funcChan, &wg)
-			funcIndex++
+	// Set up variables for synchronization and communication.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var monitor *budgetMonitor
+	if conf.MemoryBudget > 0 {
+		monitor = newBudgetMonitor(conf.MemoryBudget)
+		monitorDone := make(chan struct{})
+		go func() {
+			monitor.run(ctx)
+			close(monitorDone)
+		}()
+		defer func() {
+			cancel()
+			<-monitorDone
+		}()
+	}
+	workers := conf.FunctionWorkers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var wg sync.WaitGroup
+	// The channel can hold the results of all functions, so the workers never block on sending
+	// while we are still spawning them.
+	funcChan := make(chan functionResult, len(jobs))
+	// The semaphore bounds the number of functions analyzed concurrently.
+	sem := make(chan struct{}, workers)
+
+	// Now, analyze the functions concurrently.
+	for i, job := range jobs {
+		i, job := i, job
+		sem <- struct{}{}
+		funcCtx, done := ctx, func() {}
+		if monitor != nil {
+			funcCtx, done = monitor.start(ctx, i)
+		}
+		wg.Add(1)
+		var funcNode ast.Node = job.funcDecl
+		if job.funcLit != nil {
+			funcNode = job.funcLit
 		}
+		funcContext := assertiontree.NewFunctionContext(
+			pass, job.funcDecl, job.funcLit, functionConfig, funcLitMap, pkgFakeIdentMap, funcContracts, nonNilResults, nilComparedVars, ctorInitFields,
+			unassignedGlobals[funcNode])
+		go func() {
+			defer func() {
+				done()
+				<-sem
+			}()
+			analyzeFunc(funcCtx, pass, job.funcDecl, funcContext, job.graph, i, funcChan, &wg)
+		}()
 	}
 
 	// Spawn another goroutine that will close the channel when all analyses are done. This makes
@@ -202,19 +299,30 @@ func run(pass *analysis.Pass) ([]annotation.FullTrigger, error) {
 	// then flatten the slice.
 	// TODO: remove this extra logic once  is done.
 	var err error
-	funcTriggers := make([][]annotation.FullTrigger, funcIndex)
+	funcTriggers := make([][]annotation.FullTrigger, len(jobs))
 	triggerCount := 0
 	funcResults := map[*types.Func]*functionResult{}
+	cancelled := make([]bool, len(jobs))
 	for r := range funcChan {
 		if r.err != nil {
+			if monitor != nil && monitor.isCancelled(r.index) && errors.Is(r.err, context.Canceled) {
+				stats.CancelledOverBudget++
+				cancelled[r.index] = true
+				continue
+			}
 			err = errors.Join(err, r.err)
 		} else {
+			stats.Analyzed++
 			funcTriggers[r.index] = r.triggers
 			triggerCount += len(r.triggers)
 
 			funcObj, ok := pass.TypesInfo.ObjectOf(r.funcDecl.Name).(*types.Func)
 			if !ok {
-				continue
+				// the fake func decl nodes created for anonymous functions are unknown to the type
+				// checker, so we look them up in the fake ident map instead
+				if funcObj, ok = pkgFakeIdentMap[r.funcDecl.Name].(*types.Func); !ok {
+					continue
+				}
 			}
 			funcRes := r
 			funcResults[funcObj] = &funcRes
@@ -223,7 +331,7 @@ func run(pass *analysis.Pass) ([]annotation.FullTrigger, error) {
 
 	// Duplicate triggers in contracted functions in the callers of the function
 	if len(funcContracts) != 0 {
-		duplicateFullTriggersFromContractedFunctionsToCallers(pass, funcContracts, funcTriggers,
+		duplicateFullTriggersFromContractedFunctionsToCallers(pass, funcContracts, funcLitMap, funcTriggers,
 			funcResults)
 	}
 
@@ -233,7 +341,16 @@ func run(pass *analysis.Pass) ([]annotation.FullTrigger, error) {
 		triggers = append(triggers, s...)
 	}
 
-	return triggers, err
+	var cancelledPos []token.Pos
+	for i, c := range cancelled {
+		if c {
+			// The names of the fake function declarations of the anonymous functions are placed
+			// at the function literals.
+			cancelledPos = append(cancelledPos, jobs[i].funcDecl.Name.Pos())
+		}
+	}
+
+	return &Result{Triggers: triggers, Stats: stats, Cancelled: cancelledPos}, err
 }
 
 // duplicateFullTriggersFromContractedFunctionsToCallers duplicates all the full triggers that have
@@ -246,6 +363,7 @@ func run(pass *analysis.Pass) ([]annotation.FullTrigger, error) {
 func duplicateFullTriggersFromContractedFunctionsToCallers(
 	pass *analysis.Pass,
 	funcContracts functioncontracts.Map,
+	funcLitMap map[*ast.FuncLit]*anonymousfunc.FuncLitInfo,
 	funcTriggers [][]annotation.FullTrigger,
 	funcResults map[*types.Func]*functionResult,
 ) {
@@ -254,7 +372,7 @@ func duplicateFullTriggersFromContractedFunctionsToCallers(
 	// callsByCtrtFunc is a mapping: contracted function -> caller -> all the call expressions
 	callsByCtrtFunc := map[*types.Func]map[*types.Func][]*ast.CallExpr{}
 	for funcObj, r := range funcResults {
-		for ctrFunc, calls := range findCallsToContractedFunctions(r.funcDecl, pass, funcContracts) {
+		for ctrFunc, calls := range findCallsToContractedFunctions(r.funcDecl, pass, funcContracts, funcLitMap) {
 			for _, call := range calls {
 				// TODO: Ideally, we should do
 				//
@@ -367,11 +485,13 @@ func duplicateFullTrigger(
 
 // findCallsToContractedFunctions finds all the calls to the contracted functions in the given
 // function, and returns a map from every called contracted function to the call expressions that
-// call it.
+// call it. The calls to anonymous functions are resolved to their fake function declarations in
+// the same way as in the assertion analysis (see assertiontree.FuncLitFromCallExpr).
 func findCallsToContractedFunctions(
 	funcNode *ast.FuncDecl,
 	pass *analysis.Pass,
 	functionContracts functioncontracts.Map,
+	funcLitMap map[*ast.FuncLit]*anonymousfunc.FuncLitInfo,
 ) map[*types.Func][]*ast.CallExpr {
 	calls := map[*types.Func][]*ast.CallExpr{}
 	ast.Inspect(funcNode, func(n ast.Node) bool {
@@ -380,14 +500,17 @@ func findCallsToContractedFunctions(
 			return true
 		}
 
-		ident := util.FuncIdentFromCallExpr(callExpr)
-		if ident == nil {
-			return true
-		}
-
-		funcObj, ok := pass.TypesInfo.ObjectOf(ident).(*types.Func)
-		if !ok {
-			return true
+		var funcObj *types.Func
+		if info, ok := funcLitMap[assertiontree.FuncLitFromCallExpr(callExpr)]; ok {
+			funcObj = info.FakeFuncObj
+		} else {
+			ident := util.FuncIdentFromCallExpr(callExpr)
+			if ident == nil {
+				return true
+			}
+			if funcObj, ok = pass.TypesInfo.ObjectOf(ident).(*types.Func); !ok {
+				return true
+			}
 		}
 
 		// TODO: for now we find the functions with only a single contract nonnil -> nonnil. If we
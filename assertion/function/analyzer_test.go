@@ -25,10 +25,10 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
-	"go.uber.org/nilaway/annotation"
 	"go.uber.org/nilaway/assertion/anonymousfunc"
 	"go.uber.org/nilaway/assertion/function/assertiontree"
 	"go.uber.org/nilaway/assertion/function/functioncontracts"
+	"go.uber.org/nilaway/assertion/function/nonnilresults"
 	"go.uber.org/nilaway/nilawaytest"
 	"go.uber.org/nilaway/util/analysishelper"
 	"golang.org/x/tools/go/analysis"
@@ -48,7 +48,7 @@ func TestAnalyzer(t *testing.T) {
 	// and convert it to an error via the result struct.
 	r, err := Analyzer.Run(nil /* pass */)
 	require.NoError(t, err)
-	require.ErrorContains(t, r.(*analysishelper.Result[[]annotation.FullTrigger]).Err, "INTERNAL PANIC")
+	require.ErrorContains(t, r.(*analysishelper.Result[*Result]).Err, "INTERNAL PANIC")
 }
 
 func TestCancelledContext(t *testing.T) {
@@ -86,8 +86,10 @@ func TestCancelledContext(t *testing.T) {
 	emptyFuncLitMap := make(map[*ast.FuncLit]*anonymousfunc.FuncLitInfo)
 	emptyPkgFakeIdentMap := make(map[*ast.Ident]types.Object)
 	emptyFuncContracts := make(functioncontracts.Map)
+	emptyNonNilResults := make(nonnilresults.Map)
 	funcContext := assertiontree.NewFunctionContext(pass, funcDecl, nil, /* funcLit */
-		funcConfig, emptyFuncLitMap, emptyPkgFakeIdentMap, emptyFuncContracts)
+		funcConfig, emptyFuncLitMap, emptyPkgFakeIdentMap, emptyFuncContracts, emptyNonNilResults,
+		nil /* nilComparedVars */, nil /* ctorInitFields */, nil /* unassignedGlobals */)
 	// (3) Set up synchronization and communication for the goroutine we are going to spawn.
 	resultChan := make(chan functionResult)
 	wg := new(sync.WaitGroup)
@@ -173,8 +175,10 @@ func TestBackpropFixpointConvergence(t *testing.T) {
 		emptyFuncLitMap := make(map[*ast.FuncLit]*anonymousfunc.FuncLitInfo)
 		emptyPkgFakeIdentMap := make(map[*ast.Ident]types.Object)
 		emptyFuncContracts := make(functioncontracts.Map)
+		emptyNonNilResults := make(nonnilresults.Map)
 		funcContext := assertiontree.NewFunctionContext(pass, funcDecl, nil, /* funcLit */
-			funcConfig, emptyFuncLitMap, emptyPkgFakeIdentMap, emptyFuncContracts)
+			funcConfig, emptyFuncLitMap, emptyPkgFakeIdentMap, emptyFuncContracts, emptyNonNilResults,
+			nil /* nilComparedVars */, nil /* ctorInitFields */, nil /* unassignedGlobals */)
 		ctrlflowResult := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
 
 		ctx, cancel := context.WithCancel(context.Background())
@@ -78,10 +78,13 @@ func backpropAcrossNode(rootNode *RootAssertionNode, node ast.Node) error {
 	case *ast.SendStmt:
 		return backpropAcrossSend(rootNode, n)
 	case *ast.ExprStmt:
+		rootNode.releaseClearedIndexNodes(n.X)
+		rootNode.releaseStoredLoads(n.X)
 		rootNode.AddComputation(n.X)
 	case *ast.GoStmt:
 		rootNode.AddComputation(n.Call)
 	case *ast.IncDecStmt:
+		rootNode.releaseIndexNodesReferring(n.X)
 		rootNode.AddComputation(n.X)
 
 	case *ast.SelectorExpr:
@@ -252,6 +255,12 @@ func backpropAcrossReturn(rootNode *RootAssertionNode, node *ast.ReturnStmt) err
 // Phase 3. mark all LHS and RHS as computed.
 // nonnil(lhs, rhs)
 func backpropAcrossAssignment(rootNode *RootAssertionNode, lhs, rhs []ast.Expr) error {
+	// The reads at the indices referring to the assigned variables or fields (e.g., `m[k]` for
+	// `k = ...`) can no longer be matched with the writes before the assignment.
+	for _, lhsVal := range lhs {
+		rootNode.releaseIndexNodesReferring(lhsVal)
+	}
+
 	// For phase 1 and 2, we will first handle a few special assignments (with early return), then
 	// if none of the special cases are hit, which means it is a normal assignment, we further
 	// delegate the process to other functions depending on if it is many-to-one or one-to-one
@@ -349,13 +358,19 @@ func backpropAcrossAssignment(rootNode *RootAssertionNode, lhs, rhs []ast.Expr)
 	// If the above code did not catch any special cases, it means this assignment is a normal
 	// assignment, and we further delegate the handling to other functions.
 	if len(lhs) == len(rhs) {
-		return backpropAcrossOneToOneAssignment(rootNode, lhs, rhs)
+		if err := backpropAcrossOneToOneAssignment(rootNode, lhs, rhs); err != nil {
+			return err
+		}
+		consumeTypedNilAssignments(rootNode, lhs, rhs)
+		return nil
 	}
 	return backpropAcrossManyToOneAssignment(rootNode, lhs, rhs)
 }
 
 // backpropAcrossRange handles range expression (e.g., "for i, v := range lst"), it is designed to
 // be called from backpropAcrossAssignment as a finer-grained handler for special assignment cases.
+// Note that the per-iteration loop variables introduced in Go 1.22 require no special handling: the
+// lhs operands are assigned at the start of every iteration regardless of the language version.
 func backpropAcrossRange(rootNode *RootAssertionNode, lhs []ast.Expr, rhs ast.Expr) error {
 	// produceAsIndex(i) marks the ith lhs expression as a range index, producing it as non-nil
 	// because it necessarily has basic type (int or char)
@@ -390,6 +405,12 @@ func backpropAcrossRange(rootNode *RootAssertionNode, lhs []ast.Expr, rhs ast.Ex
 
 	rhsType := rootNode.Pass().TypesInfo.Types[rhs].Type
 
+	// Range over functions (i.e., iterators like `iter.Seq` and `iter.Seq2`) in Go 1.23, where
+	// the lhs operands are the values passed to the `yield` function by the iterator.
+	if _, ok := rhsType.Underlying().(*types.Signature); ok {
+		return backpropAcrossRangeFunc(rootNode, lhs, rhs)
+	}
+
 	// This block breaks down the cases for the `range` statement being analyzed,
 	// starting by switching on how many left-hand operands there are
 	switch len(lhs) {
@@ -415,9 +436,10 @@ func backpropAcrossRange(rootNode *RootAssertionNode, lhs []ast.Expr, rhs ast.Ex
 			return nil
 		}
 
-		// Here the range is over basic types, such as integers (e.g., "for i := range 10").
+		// Here the range is over basic types, such as integers (e.g., "for i := range 10"),
+		// possibly through named types (e.g., "for i := range n" where `n` is of type `type N int`).
 		// We do not need to do anything here, as the basic types are always presumed to be non-nil.
-		if _, ok := rhsType.(*types.Basic); ok {
+		if _, ok := rhsType.Underlying().(*types.Basic); ok {
 			return nil
 		}
 
@@ -436,6 +458,62 @@ func backpropAcrossRange(rootNode *RootAssertionNode, lhs []ast.Expr, rhs ast.Ex
 	return nil
 }
 
+// backpropAcrossRangeFunc handles range statements over functions (e.g., `for k, v := range seq`
+// where `seq` is of type `func(yield func(K, V) bool)`), it is designed to be called from
+// backpropAcrossRange. The lhs operands are the values passed to the `yield` function, i.e., the
+// params of the first (function-typed) param of the iterator. If the iterator is a declared function
+// or method (possibly converted to a named iterator type, e.g., `iter.Seq[*T](f)`), their
+// nilability is given by the callback annotations of the iterator (e.g.,
+// `// nilable(yield.param 0)`), which are in turn checked against the calls to `yield` in the body
+// of the iterator. Otherwise (e.g., the iterator is returned by a function call as an anonymous
+// function), we optimistically produce the lhs operands as nonnil.
+// TODO: handle the iterators created from anonymous functions.
+func backpropAcrossRangeFunc(rootNode *RootAssertionNode, lhs []ast.Expr, rhs ast.Expr) error {
+	rhs = astutil.Unparen(rhs)
+	// Unwrap the conversions to the iterator types, e.g., `iter.Seq[*T](f)`.
+	for {
+		call, ok := rhs.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 || !rootNode.Pass().TypesInfo.Types[call.Fun].IsType() {
+			break
+		}
+		rhs = astutil.Unparen(call.Args[0])
+	}
+
+	var funcObj *types.Func
+	switch rhs := rhs.(type) {
+	case *ast.Ident:
+		funcObj, _ = rootNode.ObjectOf(rhs).(*types.Func)
+	case *ast.SelectorExpr:
+		funcObj, _ = rootNode.ObjectOf(rhs.Sel).(*types.Func)
+	}
+	conf := rootNode.Pass().ResultOf[config.Analyzer].(*config.Config)
+	if funcObj != nil && (!conf.IsPkgInScope(funcObj.Pkg()) || annotation.CallbackSignature(funcObj, 0) == nil) {
+		funcObj = nil
+	}
+
+	for i, l := range lhs {
+		if util.IsEmptyExpr(l) {
+			continue
+		}
+		if funcObj == nil {
+			rootNode.AddProduction(&annotation.ProduceTrigger{
+				Annotation: &annotation.ProduceTriggerNever{},
+				Expr:       l,
+			})
+			continue
+		}
+		rootNode.AddProduction(&annotation.ProduceTrigger{
+			Annotation: &annotation.CallbackParam{
+				TriggerIfNilable: &annotation.TriggerIfNilable{
+					Ann: annotation.CallbackKeyFromParamNum(funcObj, 0, i),
+				},
+			},
+			Expr: l,
+		})
+	}
+	return nil
+}
+
 // backpropAcrossTypeSwitch handles type switches (e.g., "switch v := a.(*type)"), it is designed
 // to be called from backpropAcrossAssignment as a finer-grained handler for special assignment
 // cases. The main reason that this case has to be handled separately is that it introduces a
@@ -467,6 +545,29 @@ func backpropAcrossTypeSwitch(rootNode *RootAssertionNode, lhs *ast.Ident, rhs a
 					// this nil check reflects programmer logic
 					return errors.New("liftedChild variable is nil")
 				}
+				// The symbolic variable has a type different from the switched expression only if
+				// its case clause lists a single type other than `nil`, so the switched expression
+				// must be holding a value of that type. Similar to type assertions, we treat such a
+				// value as nonnil if it is a pointer (or as an unhandled expression in strict mode).
+				// A value of an interface type is nonnil as well, since a nil interface only matches
+				// the `nil` case. Other nilable values (e.g., nil maps and slices) are commonly stored
+				// in interfaces, so they are handled as the switched expression itself below.
+				caseType := varChild.decl.Type()
+				if isPointerOrInterface(caseType) && !types.Identical(caseType, rootNode.Pass().TypesInfo.TypeOf(rhs)) {
+					liftedChild.SetParent(rootNode)
+					if producers := rootNode.unhandledExprProducers(rhs); len(producers) == 1 {
+						rootNode.triggerProductions(liftedChild, &annotation.ProduceTrigger{
+							Annotation: producers[0].GetShallow().Annotation,
+							Expr:       lhs,
+						})
+						continue
+					}
+					rootNode.triggerProductions(liftedChild, &annotation.ProduceTrigger{
+						Annotation: &annotation.ProduceTriggerNever{},
+						Expr:       lhs,
+					})
+					continue
+				}
 				rhsPath, rhsProducers := rootNode.ParseExprAsProducer(rhs, false)
 				if rhsPath != nil {
 					// rhs is trackable, so move assertions as we would in the vanilla assignment case
@@ -498,6 +599,15 @@ func backpropAcrossTypeSwitch(rootNode *RootAssertionNode, lhs *ast.Ident, rhs a
 	return nil
 }
 
+// isPointerOrInterface returns true iff the underlying type of t is a pointer or an interface.
+func isPointerOrInterface(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		return true
+	}
+	return false
+}
+
 // backpropAcrossOneToOneAssignment handles normal one-to-one assignment (e.g, "var a *int = b", or
 // "var a, b, c *int = d, e, f"), it is designed to be called from backpropAcrossAssignment as a
 // finer-grained handler for one-to-one normal assignments.
@@ -514,6 +624,15 @@ func backpropAcrossOneToOneAssignment(rootNode *RootAssertionNode, lhs, rhs []as
 		}
 		parsedLHS[i] = seq
 	}
+	// Functions assigned to function-typed fields (i.e., callbacks) must match their annotations
+	for i := range lhs {
+		if sel, ok := astutil.Unparen(lhs[i]).(*ast.SelectorExpr); ok {
+			if fld, ok := rootNode.ObjectOf(sel.Sel).(*types.Var); ok && fld.IsField() {
+				rootNode.addFieldCallbackTriggers(fld, rhs[i])
+			}
+		}
+	}
+
 	// Phase 1
 
 	// We now have n expressions on the RHS and n on the LHS
@@ -830,7 +949,7 @@ func BackpropAcrossFunc(
 	// Generate rick check effects.
 	richCheckBlocks, exprNonceMap := genInitialRichCheckEffects(graph, functionContext)
 	richCheckBlocks = propagateRichChecks(graph, richCheckBlocks)
-	blocks, preprocessing := blocksAndPreprocessingFromCFG(pass, graph, richCheckBlocks)
+	blocks, preprocessing := blocksAndPreprocessingFromCFG(pass, graph, richCheckBlocks, functionContext.functionConfig)
 
 	// The assertion nodes for each block and an array of bools to indicate whether each block is
 	// updated in this round or not.
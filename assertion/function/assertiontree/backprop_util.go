@@ -202,6 +202,15 @@ func computeAndConsumeResults(rootNode *RootAssertionNode, node *ast.ReturnStmt)
 		)
 	}
 
+	// a nil pointer returned as an `error` becomes a typed nil, while the callers conventionally
+	// compare the error against nil
+	sig := rootNode.FuncObj().Type().(*types.Signature)
+	for i := range node.Results {
+		if types.Identical(sig.Results().At(i).Type(), util.ErrorType) {
+			rootNode.consumeIfTypedNil(node.Results[i], util.ErrorType)
+		}
+	}
+
 	if ok := handleErrorReturns(rootNode, node, node.Results, false /* isNamedReturn */); ok {
 		return nil
 	}
@@ -285,8 +294,10 @@ func handleErrorReturns(rootNode *RootAssertionNode, retStmt *ast.ReturnStmt, re
 			}
 		}
 	} else if isErrorReturnNonnil(rootNode, errRetExpr) {
-		// create consume trigger for only the error return
+		// create consume trigger for only the error return, and the non-error returns that escape
+		// from the guarding of the error return
 		createConsumerForErrorReturn(rootNode, errRetExpr, errRetIndex, retStmt, isNamedReturn)
+		createUnguardedReturnConsumers(rootNode, nonErrRetExpr, retStmt, isNamedReturn)
 	} else {
 		// the nilability of error return is unknown, hence create special consume triggers for all returns
 		createSpecialConsumersForAllReturns(rootNode, nonErrRetExpr, errRetExpr, errRetIndex, retStmt, isNamedReturn)
@@ -331,12 +342,30 @@ func handleBooleanReturns(rootNode *RootAssertionNode, retStmt *ast.ReturnStmt,
 
 	// If return is "true", then track its n-1 returns. Create return consume triggers for all n-1 return expressions.
 	// If return is "false", then do nothing, since we don't track boolean values.
+	// The only exception is the n-1 returns that escape from the guarding of the boolean return,
+	// which are tracked regardless.
 	if val {
 		createGeneralReturnConsumers(rootNode, nMinusOneRetExpr, retStmt, isNamedReturn)
+	} else {
+		createUnguardedReturnConsumers(rootNode, nMinusOneRetExpr, retStmt, isNamedReturn)
 	}
 	return true
 }
 
+// consumeTypedNilAssignments adds consumptions for the values of concrete pointer types assigned to
+// interface variables that are compared against nil, since nil pointers would become typed nils there
+func consumeTypedNilAssignments(rootNode *RootAssertionNode, lhs, rhs []ast.Expr) {
+	for i := range lhs {
+		ident, ok := lhs[i].(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if v, ok := rootNode.ObjectOf(ident).(*types.Var); ok && rootNode.isNilCompared(v) {
+			rootNode.consumeIfTypedNil(rhs[i], v.Type())
+		}
+	}
+}
+
 // createConsumerForErrorReturn creates a consumer for the error return enforcing it to be non-nil
 func createConsumerForErrorReturn(rootNode *RootAssertionNode, errRetExpr ast.Expr, errRetIndex int, retStmt *ast.ReturnStmt, isNamedReturn bool) {
 	rootNode.AddConsumption(&annotation.ConsumeTrigger{
@@ -371,11 +400,34 @@ func createGeneralReturnConsumers(rootNode *RootAssertionNode, results []ast.Exp
 	}
 }
 
+// createUnguardedReturnConsumers creates general return consumers for the non-error (or non-boolean) return
+// expressions that are annotated to escape from the guarding of the last return (e.g., "always-nonnil"), since they
+// must be nonnil regardless of the last return
+func createUnguardedReturnConsumers(rootNode *RootAssertionNode, results []ast.Expr, retStmt *ast.ReturnStmt, isNamedReturn bool) {
+	for i := range results {
+		// don't do anything if the expression is a blank identifier ("_")
+		if util.IsEmptyExpr(results[i]) || !rootNode.isResultUnguarded(i) {
+			continue
+		}
+		rootNode.AddConsumption(&annotation.ConsumeTrigger{
+			Annotation: &annotation.UseAsReturn{
+				TriggerIfNonNil: &annotation.TriggerIfNonNil{
+					Ann: annotation.RetKeyFromRetNum(rootNode.FuncObj(), i)},
+				IsNamedReturn: isNamedReturn,
+				RetStmt:       retStmt},
+			Expr:   results[i],
+			Guards: util.NoGuards(),
+		})
+	}
+}
+
 // createSpecialConsumersForAllReturns conservatively creates specially designed consumers for all return expressions, error and non-error
 func createSpecialConsumersForAllReturns(rootNode *RootAssertionNode, nonErrRetExpr []ast.Expr, errRetExpr ast.Expr, errRetIndex int, retStmt *ast.ReturnStmt, isNamedReturn bool) {
+	// the non-error returns that escape from the guarding of the error return do not depend on it
+	createUnguardedReturnConsumers(rootNode, nonErrRetExpr, retStmt, isNamedReturn)
 	for i := range nonErrRetExpr {
 		// don't do anything if the expression is a blank identifier ("_")
-		if util.IsEmptyExpr(nonErrRetExpr[i]) {
+		if util.IsEmptyExpr(nonErrRetExpr[i]) || rootNode.isResultUnguarded(i) {
 			continue
 		}
 		consumer := &annotation.ConsumeTrigger{
@@ -653,7 +705,7 @@ func composeRootFuncs(f1, f2 RootFunc) RootFunc {
 //
 // nonnil(result 0, result 1)
 func blocksAndPreprocessingFromCFG(
-	pass *analysis.Pass, graph *cfg.CFG, richCheckBlocks [][]RichCheckEffect) (
+	pass *analysis.Pass, graph *cfg.CFG, richCheckBlocks [][]RichCheckEffect, functionConfig FunctionConfig) (
 	[]*cfg.Block, []*preprocessPair) {
 
 	numBlocks := len(graph.Blocks)
@@ -685,7 +737,7 @@ func blocksAndPreprocessingFromCFG(
 
 			// so add nil check productions to each successor
 			// this is where the assumption that True Name = Succs[0], False Name = Succs[1] shows up
-			trueNilCheck, falseNilCheck, isNoop := AddNilCheck(pass, cond)
+			trueNilCheck, falseNilCheck, isNoop := AddNilCheck(pass, cond, functionConfig)
 			if !isNoop {
 				// we've discovered that this is a nil check
 				preprocessing[i] = &preprocessPair{
@@ -20,6 +20,7 @@ import (
 	"go/types"
 
 	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion/function/trustedfunc"
 	"go.uber.org/nilaway/util"
 )
 
@@ -41,6 +42,10 @@ func (f *funcAssertionNode) DefaultTrigger() annotation.ProducingAnnotationTrigg
 		panic("only functions with singular result should be entered into the assertion tree")
 	}
 
+	if trustedfunc.HasNilableResult(f.decl) {
+		return &annotation.TrustedFuncNilable{ProduceTriggerTautology: &annotation.ProduceTriggerTautology{}}
+	}
+
 	if f.decl.Type().(*types.Signature).Recv() != nil {
 		return &annotation.MethodReturn{
 			TriggerIfNilable: &annotation.TriggerIfNilable{
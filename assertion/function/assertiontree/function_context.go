@@ -18,8 +18,10 @@ import (
 	"go/ast"
 	"go/types"
 
+	"go.uber.org/nilaway/annotation"
 	"go.uber.org/nilaway/assertion/anonymousfunc"
 	"go.uber.org/nilaway/assertion/function/functioncontracts"
+	"go.uber.org/nilaway/assertion/function/nonnilresults"
 	"golang.org/x/tools/go/analysis"
 )
 
@@ -63,6 +65,24 @@ type FunctionContext struct {
 
 	// funcContracts stores the function contracts of all the functions.
 	funcContracts functioncontracts.Map
+
+	// nonNilResults stores the results that are annotated to escape from the guarding by the last
+	// (error or ok) results of all the functions.
+	nonNilResults nonnilresults.Map
+
+	// nilComparedVars stores the interface-typed variables in the package that are compared against
+	// nil, hence must not hold typed nils (see CollectNilComparedVars).
+	nilComparedVars map[*types.Var]bool
+
+	// ctorInitFields stores the fields in the package that are treated as nonnil since they are always
+	// set by the constructors of their struct types (see
+	// annotation.ObservedMap.ConstructorInitializedFields).
+	ctorInitFields map[*types.Var]bool
+
+	// unassignedGlobals stores the global variables assigned in init() functions that may still be
+	// zero-valued at the entry of this function, since it may run before the assignments (see
+	// global.UnassignedGlobals).
+	unassignedGlobals map[*types.Var]bool
 }
 
 // FunctionConfig is meant to hold all the user set configuration for analyzing a function
@@ -71,6 +91,9 @@ type FunctionConfig struct {
 	EnableStructInitCheck bool
 	// EnableAnonymousFunc is a flag to enable checking anonymous functions.
 	EnableAnonymousFunc bool
+	// StrictMode is a flag to disable the optimistic assumptions that are made to reduce false
+	// positives, e.g., that `len(a) == len(b)` implies both `a` and `b` are non-nil.
+	StrictMode bool
 }
 
 // NewFunctionContext returns a new FunctionContext and initializes all the maps
@@ -82,6 +105,10 @@ func NewFunctionContext(
 	funcLitMap map[*ast.FuncLit]*anonymousfunc.FuncLitInfo,
 	pkgFakeIdentMap map[*ast.Ident]types.Object,
 	funcContracts functioncontracts.Map,
+	nonNilResults nonnilresults.Map,
+	nilComparedVars map[*types.Var]bool,
+	ctorInitFields map[*types.Var]bool,
+	unassignedGlobals map[*types.Var]bool,
 ) FunctionContext {
 	return FunctionContext{
 		pass:                    pass,
@@ -93,7 +120,27 @@ func NewFunctionContext(
 		funcLitMap:              funcLitMap,
 		pkgFakeIdentMap:         pkgFakeIdentMap,
 		funcContracts:           funcContracts,
+		nonNilResults:           nonNilResults,
+		nilComparedVars:         nilComparedVars,
+		ctorInitFields:          ctorInitFields,
+		unassignedGlobals:       unassignedGlobals,
+	}
+}
+
+// globalVarProducer returns the producer for reading the global variable at the entry of the
+// function.
+func (fc *FunctionContext) globalVarProducer(v *types.Var) annotation.ProducingAnnotationTrigger {
+	if fc.unassignedGlobals[v] {
+		return &annotation.GlobalVarReadBeforeInit{
+			ProduceTriggerTautology: &annotation.ProduceTriggerTautology{},
+			VarObj:                  v,
+		}
 	}
+	return &annotation.GlobalVarRead{
+		TriggerIfNilable: &annotation.TriggerIfNilable{
+			Ann: &annotation.GlobalVarAnnotationKey{
+				VarDecl: v,
+			}}}
 }
 
 // getCachedSelectorExpr returns cached selector expression. It returns artificially created ast expression. Which is cached to
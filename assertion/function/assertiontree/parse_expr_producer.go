@@ -103,12 +103,8 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 				}
 				if annotation.VarIsGlobal(varObj) {
 					return &annotation.ProduceTrigger{
-						Annotation: &annotation.GlobalVarRead{
-							TriggerIfNilable: &annotation.TriggerIfNilable{
-								Ann: &annotation.GlobalVarAnnotationKey{
-									VarDecl: varObj,
-								}}},
-						Expr: expr,
+						Annotation: r.functionContext.globalVarProducer(varObj),
+						Expr:       expr,
 					}
 				}
 				// in the case of a totally unrecognized identifier - we assume nilability
@@ -250,6 +246,15 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 		// to try to subsume this switch with funcIdentFromCallExpr
 		switch fun := expr.Fun.(type) {
 		case *ast.Ident: // direct function call
+			if cb, ok := r.callbackOf(fun); ok {
+				// call to a function-typed parameter (i.e., a callback) of the current function
+				return nil, r.getCallbackResultProducers(cb, expr)
+			}
+			if funcIdent := getFuncIdent(expr, &r.functionContext); funcIdent != fun {
+				// call to an anonymous function through the variable it is assigned to, whose
+				// results are produced by the fake function declaration created for it
+				return nil, r.getFuncReturnProducers(funcIdent, expr)
+			}
 			if !r.isFunc(fun) {
 				// The following block implements the basic support for append function where it has
 				// only two arguments and the first argument is the same as the lhs of assignment.
@@ -291,6 +296,10 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 			return nil, r.getFuncReturnProducers(fun, expr)
 
 		case *ast.SelectorExpr: // method call
+			if cb, ok := r.callbackOf(fun); ok {
+				// call to a function-typed field (i.e., a callback)
+				return nil, r.getCallbackResultProducers(cb, expr)
+			}
 			if !r.isFunc(fun.Sel) {
 				// we assume builtins and type casts don't return nil
 				return nil, nil
@@ -318,7 +327,7 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 			// this could result from calling a function returned anonymously from another function, such as f(4)(3), and
 			// although theoretically we should track that, we're going to leave it as an unhandled edge case for now
 			// TODO: consider handling this case (and similar case in backPropAcrossReturn)
-			return nil, nil
+			return nil, r.unhandledExprProducers(expr)
 		}
 	case *ast.IndexExpr:
 		recv, rproducers := r.ParseExprAsProducer(expr.X, false)
@@ -330,35 +339,35 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 			// X part of the expression is trackable. Now we need to check if the index is stable or trackable.
 			// If the index is not stable, it is still considered trackable if it falls into any of these categories:
 			// - Index is a variable (e.g., `m[i]`)
-			// - Index is a built-in function (e.g., `m[len(m)-1]`)
+			// - Index is a built-in function or a type conversion with trackable arguments (e.g., `m[len(m)-1]`,
+			//   `m[string(b)]`)
 			// - Index is a field selector chain (e.g., `m[g.h.i]`)
-			// TODO: above non-literal indices should only be considered trackable if no reassignment is found between
-			//  accesses. For example, `i := 0; if m[i] != nil { i = 10; return *m[i] }` should not be considered trackable
-			//  as the index `i` is reassigned between accesses. Towards, we plan to add another analyzer pass based on
-			//  SSA to determine if the index is reassigned between accesses.
+			// Note that the non-literal indices are tracked only until the variables or fields they refer to are
+			// reassigned, see releaseIndexNodesReferring.
 			var isIndexTrackable func(expr ast.Expr) bool
 			isIndexTrackable = func(expr ast.Expr) bool {
 				switch index := expr.(type) {
 				case *ast.Ident:
 					return r.isVariable(index) || r.isStable(expr)
+				case *ast.ParenExpr:
+					return isIndexTrackable(index.X)
 				case *ast.BinaryExpr:
 					if index.Op == token.SUB || index.Op == token.ADD {
 						return isIndexTrackable(index.X) && isIndexTrackable(index.Y)
 					}
 					return false
 				case *ast.CallExpr:
-					if fun, ok := index.Fun.(*ast.Ident); ok {
-						if r.isBuiltIn(fun) {
-							// iterate over the arguments of the call expression
-							for _, arg := range index.Args {
-								if !isIndexTrackable(arg) {
-									return false
-								}
-							}
-							return true
+					// user-defined functions are not considered stable, see the maps test data
+					if fun, ok := index.Fun.(*ast.Ident); !ok || (!r.isBuiltIn(fun) && !r.isTypeName(fun)) {
+						return false
+					}
+					// iterate over the arguments of the call expression
+					for _, arg := range index.Args {
+						if !isIndexTrackable(arg) {
+							return false
 						}
 					}
-					return false
+					return true
 				case *ast.SelectorExpr:
 					return util.IsFieldSelectorChain(index)
 				default:
@@ -425,6 +434,8 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 			if s := util.TypeAsDeeplyStruct(r.Pass().TypesInfo.TypeOf(expr.X)); s != nil {
 				return r.ParseExprAsProducer(expr.X, doNotTrack)
 			}
+			// taking the address of an expression never yields nil
+			return nil, nil
 		}
 	case *ast.ParenExpr:
 		// simply parse the underlying expression
@@ -438,15 +449,48 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 			}
 		}
 		return nil, nil
+	case *ast.FuncLit:
+		// function literals are never nil
+		return nil, nil
+	}
+	// TODO: right now this default case assumes that unhandled expressions are non-nil (unless
+	//  strict mode is enabled), consider changing this
+	return nil, r.unhandledExprProducers(expr)
+}
+
+// unhandledExprProducers returns the producers for an expression whose nilability we do not model.
+// By default, we optimistically assume such expressions are non-nil and return nil. In strict mode,
+// we instead conservatively assume each of the values the expression evaluates to is nilable.
+func (r *RootAssertionNode) unhandledExprProducers(expr ast.Expr) []producer.ParsedProducer {
+	if !r.functionContext.functionConfig.StrictMode || util.ExprBarsNilness(r.Pass(), expr) {
+		return nil
+	}
+
+	numValues := 1
+	if tuple, ok := r.Pass().TypesInfo.TypeOf(expr).(*types.Tuple); ok {
+		numValues = tuple.Len()
+	}
+	producers := make([]producer.ParsedProducer, numValues)
+	for i := range producers {
+		producers[i] = producer.ShallowParsedProducer{Producer: &annotation.ProduceTrigger{
+			Annotation: &annotation.UnhandledExpr{ProduceTriggerTautology: &annotation.ProduceTriggerTautology{}},
+			Expr:       expr,
+		}}
 	}
-	// TODO: right now this default case assumes that unhandled expressions are non-nil, consider changing this
-	return nil, nil
+	return producers
 }
 
 // getFuncReturnProducers returns a list of producers that are triggered at the call expression
 func (r *RootAssertionNode) getFuncReturnProducers(ident *ast.Ident, expr *ast.CallExpr) []producer.ParsedProducer {
 	funcObj := r.ObjectOf(ident).(*types.Func)
 
+	if trustedfunc.HasNilableResult(funcObj) {
+		return []producer.ParsedProducer{producer.ShallowParsedProducer{Producer: &annotation.ProduceTrigger{
+			Annotation: &annotation.TrustedFuncNilable{ProduceTriggerTautology: &annotation.ProduceTriggerTautology{}},
+			Expr:       expr,
+		}}}
+	}
+
 	numResults := util.FuncNumResults(funcObj)
 	isErrReturning := util.FuncIsErrReturning(funcObj)
 	isOkReturning := util.FuncIsOkReturning(funcObj)
@@ -476,10 +520,10 @@ func (r *RootAssertionNode) getFuncReturnProducers(ident *ast.Ident, expr *ast.C
 					TriggerIfNilable: &annotation.TriggerIfNilable{
 						Ann: retKey,
 
-						// for an error-returning function, all but the last result are guarded
-						// TODO: add an annotation that allows more results to escape from guarding
-						// such as "error-nonnil" or "always-nonnil"
-						NeedsGuard: (isErrReturning || isOkReturning) && i != numResults-1,
+						// for an error-returning function, all but the last result are guarded,
+						// unless they are annotated to escape from guarding (e.g., "always-nonnil")
+						NeedsGuard: (isErrReturning || isOkReturning) && i != numResults-1 &&
+							!r.functionContext.nonNilResults.IsUnguarded(funcObj, i),
 					},
 				},
 				Expr: expr,
@@ -494,6 +538,25 @@ func (r *RootAssertionNode) getFuncReturnProducers(ident *ast.Ident, expr *ast.C
 	return producers
 }
 
+// getCallbackResultProducers returns a list of producers that are triggered at the call expression
+// to the callback
+func (r *RootAssertionNode) getCallbackResultProducers(cb callback, expr *ast.CallExpr) []producer.ParsedProducer {
+	producers := make([]producer.ParsedProducer, cb.sig.Results().Len())
+	for i := range producers {
+		producers[i] = producer.ShallowParsedProducer{
+			Producer: &annotation.ProduceTrigger{
+				Annotation: &annotation.CallbackResult{
+					TriggerIfNilable: &annotation.TriggerIfNilable{
+						Ann: cb.retKey(i),
+					},
+				},
+				Expr: expr,
+			},
+		}
+	}
+	return producers
+}
+
 // parseStructCreateExprAsProducer parses composite expressions used to initialize a struct e.g. A{f1: v1, f2: v2}
 func (r *RootAssertionNode) parseStructCreateExprAsProducer(expr ast.Expr, fieldInitializations []ast.Expr) producer.ParsedProducer {
 	exprType := r.Pass().TypesInfo.TypeOf(expr)
@@ -21,8 +21,10 @@ import (
 	"go/types"
 
 	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion/function/trustedfunc"
 	"go.uber.org/nilaway/util"
 	"go.uber.org/nilaway/util/asthelper"
+	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/cfg"
 )
 
@@ -179,6 +181,241 @@ type FuncOkReturn struct {
 	okRead
 }
 
+// A BoolVarNilCheck is a RichCheckEffect for a boolean variable that captures the result of a nil
+// check, such as `valid` in `valid := x != nil && x.f != nil` or `missing` in `missing := u == nil`.
+// Branching on the variable later (e.g., `if !valid { return }`) has the same effects as branching on
+// the captured expression directly, as long as neither the variable nor any variable referenced by
+// the captured expression is assigned in between.
+type BoolVarNilCheck struct {
+	root *RootAssertionNode // an associated root node
+	// boolVar is the boolean variable that stores the result of the check
+	boolVar *types.Var
+	// check is the boolean expression stored in `boolVar`, which contains at least one nil check
+	check ast.Expr
+	// referenced is the set of variables that `check` reads from, including `boolVar` itself
+	referenced map[*types.Var]bool
+}
+
+func (b *BoolVarNilCheck) isTriggeredBy(expr ast.Expr) bool {
+	ident, ok := astutil.Unparen(expr).(*ast.Ident)
+	return ok && b.root.ObjectOf(ident) == b.boolVar
+}
+
+func (b *BoolVarNilCheck) isInvalidatedBy(node ast.Node) bool {
+	lhs, _ := asthelper.ExtractLHSRHS(node)
+	if incDec, ok := node.(*ast.IncDecStmt); ok {
+		lhs = []ast.Expr{incDec.X}
+	}
+	for _, expr := range lhs {
+		// Any write to (or through) a variable referenced by the check, such as `x = nil` or
+		// `x.f = nil`, may change the outcome of the check, so we conservatively give up.
+		invalidated := false
+		ast.Inspect(expr, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok {
+				if v, ok := b.root.ObjectOf(ident).(*types.Var); ok && b.referenced[v] {
+					invalidated = true
+				}
+			}
+			return !invalidated
+		})
+		if invalidated {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *BoolVarNilCheck) effectIfTrue(node *RootAssertionNode) {
+	trueCheck, _, _ := addBoolNilCheck(node, b.check)
+	trueCheck(node)
+}
+
+func (b *BoolVarNilCheck) effectIfFalse(node *RootAssertionNode) {
+	_, falseCheck, _ := addBoolNilCheck(node, b.check)
+	falseCheck(node)
+}
+
+func (*BoolVarNilCheck) isNoop() bool { return false }
+
+func (b *BoolVarNilCheck) equals(effect RichCheckEffect) bool {
+	other, ok := effect.(*BoolVarNilCheck)
+	if !ok {
+		return false
+	}
+	return b.boolVar == other.boolVar && b.check == other.check
+}
+
+// addBoolNilCheck extends AddNilCheck to compound boolean expressions that are stored in variables
+// instead of being branched on directly (where the CFG preprocessing already splits them). The
+// true branch of `a && b` implies the true effects of both operands, the false branch of `a || b`
+// implies the false effects of both operands, and `!a` swaps the effects of `a`.
+func addBoolNilCheck(rootNode *RootAssertionNode, expr ast.Expr) (trueCheck, falseCheck RootFunc, isNoop bool) {
+	noop := func(*RootAssertionNode) {}
+
+	expr = astutil.Unparen(expr)
+	switch e := expr.(type) {
+	case *ast.UnaryExpr:
+		if e.Op == token.NOT {
+			trueCheck, falseCheck, isNoop = addBoolNilCheck(rootNode, e.X)
+			return falseCheck, trueCheck, isNoop
+		}
+	case *ast.BinaryExpr:
+		if e.Op == token.LAND || e.Op == token.LOR {
+			xTrue, xFalse, xNoop := addBoolNilCheck(rootNode, e.X)
+			yTrue, yFalse, yNoop := addBoolNilCheck(rootNode, e.Y)
+			if xNoop && yNoop {
+				return noop, noop, true
+			}
+			if e.Op == token.LAND {
+				return composeRootFuncs(yTrue, xTrue), noop, false
+			}
+			return noop, composeRootFuncs(yFalse, xFalse), false
+		}
+	}
+	return AddNilCheck(rootNode.Pass(), expr, rootNode.functionContext.functionConfig)
+}
+
+// NodeTriggersBoolVarNilCheck is a case of a node creating a rich check effect for boolean variables
+// that capture nil checks. Specifically, it matches on `AssignStmt`s and `ValueSpec`s of the form
+// - `valid := x != nil && x.f != nil`
+// - `var missing = u == nil`
+func NodeTriggersBoolVarNilCheck(rootNode *RootAssertionNode, node ast.Node) ([]RichCheckEffect, bool) {
+	if assign, ok := node.(*ast.AssignStmt); ok && assign.Tok != token.DEFINE && assign.Tok != token.ASSIGN {
+		return nil, false
+	}
+	lhs, rhs := asthelper.ExtractLHSRHS(node)
+	if len(lhs) != len(rhs) {
+		return nil, false
+	}
+
+	var effects []RichCheckEffect
+	for i := range lhs {
+		ident, ok := lhs[i].(*ast.Ident)
+		if !ok {
+			continue
+		}
+		boolVar, ok := rootNode.ObjectOf(ident).(*types.Var)
+		if !ok {
+			continue
+		}
+		if _, _, isNoop := addBoolNilCheck(rootNode, rhs[i]); isNoop {
+			continue
+		}
+
+		referenced := map[*types.Var]bool{boolVar: true}
+		ast.Inspect(rhs[i], func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok {
+				if v, ok := rootNode.ObjectOf(ident).(*types.Var); ok {
+					referenced[v] = true
+				}
+			}
+			return true
+		})
+		effects = append(effects, &BoolVarNilCheck{
+			root:       rootNode,
+			boolVar:    boolVar,
+			check:      rhs[i],
+			referenced: referenced,
+		})
+	}
+	if len(effects) > 0 {
+		return effects, true
+	}
+	return nil, false
+}
+
+// An ErrorsAsTarget is a RichCheckEffect for the target of `errors.As(err, &target)`, which is
+// assigned a non-nil value if the call returns true. To have the intended effect, either the call
+// itself must be branched on (e.g., `if errors.As(err, &target) { }`), or its result must be stored
+// in a variable that is branched on (e.g., `ok := errors.As(err, &target); if ok { }`) before an
+// assignment to either the variable or `target`.
+type ErrorsAsTarget struct {
+	root       *RootAssertionNode // an associated root node
+	call       *ast.CallExpr      // the call to `errors.As`
+	ok         TrackableExpr      // the variable storing the result of the call, nil if none
+	target     TrackableExpr      // the target of the call
+	targetExpr ast.Expr           // the expression of the target of the call
+}
+
+func (e *ErrorsAsTarget) isTriggeredBy(expr ast.Expr) bool {
+	if e.ok == nil {
+		return astutil.Unparen(expr) == e.call
+	}
+	return exprMatchesTrackableExpr(e.root, expr, e.ok)
+}
+
+func (e *ErrorsAsTarget) isInvalidatedBy(node ast.Node) bool {
+	if e.ok == nil {
+		return false
+	}
+	return nodeAssignsOneWithoutOther(e.root, node, e.ok, e.target) ||
+		nodeAssignsOneWithoutOther(e.root, node, e.target, e.ok)
+}
+
+func (e *ErrorsAsTarget) effectIfTrue(node *RootAssertionNode) {
+	produceExprByTrigger(e.targetExpr, &annotation.TrustedFuncNonnil{ProduceTriggerNever: &annotation.ProduceTriggerNever{}})(node)
+}
+
+func (e *ErrorsAsTarget) effectIfFalse(*RootAssertionNode) {
+	// no-op
+}
+
+func (*ErrorsAsTarget) isNoop() bool { return false }
+
+func (e *ErrorsAsTarget) equals(effect RichCheckEffect) bool {
+	other, ok := effect.(*ErrorsAsTarget)
+	if !ok || e.call != other.call {
+		return false
+	}
+	if e.ok == nil || other.ok == nil {
+		return e.ok == nil && other.ok == nil
+	}
+	return e.root.Equal(e.ok, other.ok)
+}
+
+// NodeTriggersErrorsAs is a case of a node creating a rich check effect for the targets of calls to
+// `errors.As`. Specifically, it matches on the calls `errors.As(err, &target)` that are branched on
+// directly, and on `AssignStmt`s of the form `ok := errors.As(err, &target)`.
+func NodeTriggersErrorsAs(rootNode *RootAssertionNode, node ast.Node) ([]RichCheckEffect, bool) {
+	var call *ast.CallExpr
+	var okParsed TrackableExpr
+	switch node := node.(type) {
+	case *ast.CallExpr:
+		// A call that appears directly as a node in the CFG (instead of being wrapped in an
+		// `ExprStmt`) is a branching condition.
+		call = node
+	default:
+		lhs, rhs := asthelper.ExtractLHSRHS(node)
+		if len(lhs) != 1 || len(rhs) != 1 {
+			return nil, false
+		}
+		rhsCall, ok := astutil.Unparen(rhs[0]).(*ast.CallExpr)
+		if !ok {
+			return nil, false
+		}
+		if okParsed = parseExpr(rootNode, lhs[0]); okParsed == nil {
+			return nil, false
+		}
+		call = rhsCall
+	}
+
+	targetExpr, ok := trustedfunc.AsErrorsAs(call, rootNode.Pass())
+	if !ok {
+		return nil, false
+	}
+	target := parseExpr(rootNode, targetExpr)
+	if target == nil {
+		return nil, false
+	}
+	return []RichCheckEffect{&ErrorsAsTarget{
+		root:       rootNode,
+		call:       call,
+		ok:         okParsed,
+		target:     target,
+		targetExpr: targetExpr,
+	}}, true
+}
+
 // A RichCheckNoop is a placeholder instance of RichCheckEffect that functions as a total noop.
 // It is used to allow in place modification of collections of RichCheckEffects.
 type RichCheckNoop struct{}
@@ -210,6 +447,12 @@ func RichCheckFromNode(rootNode *RootAssertionNode, nonceGenerator *util.GuardNo
 	if funcEffects, ok := NodeTriggersFuncErrRet(rootNode, nonceGenerator, node); ok {
 		effects, someEffects = append(effects, funcEffects...), true
 	}
+	if boolVarEffects, ok := NodeTriggersBoolVarNilCheck(rootNode, node); ok {
+		effects, someEffects = append(effects, boolVarEffects...), true
+	}
+	if errorsAsEffects, ok := NodeTriggersErrorsAs(rootNode, node); ok {
+		effects, someEffects = append(effects, errorsAsEffects...), true
+	}
 	return effects, someEffects
 }
 
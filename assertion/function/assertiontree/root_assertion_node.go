@@ -22,6 +22,7 @@ import (
 	"go/types"
 
 	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion/function/trustedfunc"
 	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/util"
 	"golang.org/x/tools/go/analysis"
@@ -61,6 +62,87 @@ func (r *RootAssertionNode) HasContract(funcObj *types.Func) bool {
 	return ok
 }
 
+// isResultUnguarded returns if the i-th result of the current function is annotated to escape from
+// the guarding by its last (error or ok) result.
+func (r *RootAssertionNode) isResultUnguarded(i int) bool {
+	return r.functionContext.nonNilResults.IsUnguarded(r.FuncObj(), i)
+}
+
+// consumeIfTypedNil adds a consumption for the expression if it is of a concrete pointer type and
+// is implicitly converted to an interface that is compared against nil, since a nil pointer would
+// become a non-nil interface value (i.e., a typed nil) there.
+func (r *RootAssertionNode) consumeIfTypedNil(expr ast.Expr, target types.Type) {
+	if !types.IsInterface(target) {
+		return
+	}
+	if t := r.Pass().TypesInfo.TypeOf(expr); t == nil || types.IsInterface(t) {
+		return
+	} else if _, ok := t.Underlying().(*types.Pointer); !ok {
+		return
+	}
+	r.AddConsumption(&annotation.ConsumeTrigger{
+		Annotation: &annotation.TypedNilInInterface{ConsumeTriggerTautology: &annotation.ConsumeTriggerTautology{}},
+		Expr:       expr,
+		Guards:     util.NoGuards(),
+	})
+}
+
+// isNilCompared returns if the variable is an interface-typed variable that is compared against
+// nil (see CollectNilComparedVars).
+func (r *RootAssertionNode) isNilCompared(v *types.Var) bool {
+	return r.functionContext.nilComparedVars[v]
+}
+
+// consumeConstructorInitializedFields adds consumptions for the values assigned to the fields in
+// the composite literal that are treated as nonnil since they are always set by the constructors
+// of their struct types (see annotation.ObservedMap.ConstructorInitializedFields). Note that other
+// assignments into such fields (e.g., `s.f = v`) are already consumed as normal field assignments.
+func (r *RootAssertionNode) consumeConstructorInitializedFields(lit *ast.CompositeLit) {
+	if len(r.functionContext.ctorInitFields) == 0 {
+		return
+	}
+	r.forEachLitField(lit, func(fld *types.Var, value ast.Expr) {
+		if !r.functionContext.ctorInitFields[fld] {
+			return
+		}
+		r.AddConsumption(&annotation.ConsumeTrigger{
+			Annotation: &annotation.FldAssign{
+				TriggerIfNonNil: &annotation.TriggerIfNonNil{
+					Ann: &annotation.FieldAnnotationKey{FieldDecl: fld},
+				},
+			},
+			Expr:   value,
+			Guards: util.NoGuards(),
+		})
+	})
+}
+
+// forEachLitField calls fn with each struct field assigned in the composite literal (in either
+// the keyed or the positional form) and the value assigned to it.
+func (r *RootAssertionNode) forEachLitField(lit *ast.CompositeLit, fn func(fld *types.Var, value ast.Expr)) {
+	structType := util.TypeAsDeeplyStruct(r.Pass().TypesInfo.TypeOf(lit))
+	if structType == nil {
+		return
+	}
+	for i, elt := range lit.Elts {
+		var fld *types.Var
+		value := elt
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			key, ok := kv.Key.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			fld, _ = r.ObjectOf(key).(*types.Var)
+			value = kv.Value
+		} else if i < structType.NumFields() {
+			fld = structType.Field(i)
+		}
+		if fld != nil {
+			fn(fld, value)
+		}
+	}
+}
+
 // MinimalString for a RootAssertionNode returns a minimal string representation of that root node
 func (r *RootAssertionNode) MinimalString() string {
 	return fmt.Sprintf("root<func: %s>", r.functionContext.funcDecl.Name)
@@ -251,7 +333,7 @@ func (r *RootAssertionNode) linkPath(path TrackableExpr) *RootAssertionNode {
 func (r *RootAssertionNode) AddConsumption(consumer *annotation.ConsumeTrigger) {
 
 	// we check if the type of the expression `expr` prevents it from ever being nil in the first place
-	if util.ExprBarsNilness(r.Pass(), consumer.Expr) {
+	if t := r.typeOf(consumer.Expr); t != nil && util.TypeBarsNilness(t) {
 		return // expr cannot be nil, so do nothing
 	}
 
@@ -517,6 +599,129 @@ func (r *RootAssertionNode) AddGuardMatch(expr ast.Expr, behavior GuardMatchBeha
 	currNode.SetConsumeTriggers(consumers)
 }
 
+// releaseIndexNodes releases the index nodes in the tree for which the predicate holds: the
+// consumers at and below each of them are matched with the default producers of their expressions
+// (e.g., a map read lacking guarding), and the nodes are removed from the tree. This is needed when
+// a later read at an index can no longer be matched with an earlier write at the same index.
+func (r *RootAssertionNode) releaseIndexNodes(pred func(*indexAssertionNode) bool) {
+	var visit func(node AssertionNode, expr ast.Expr)
+	visit = func(node AssertionNode, expr ast.Expr) {
+		for i := 0; i < len(node.Children()); i++ {
+			child := node.Children()[i]
+			childExpr := child.BuildExpr(expr)
+			if index, ok := child.(*indexAssertionNode); ok && pred(index) {
+				r.triggerProductions(child, &annotation.ProduceTrigger{
+					Annotation: child.DefaultTrigger(),
+					Expr:       childExpr,
+				})
+				detachFromParent(child, i)
+				i--
+				continue
+			}
+			visit(child, childExpr)
+		}
+	}
+	visit(r, nil)
+}
+
+// releaseIndexNodesReferring releases the index nodes whose indices refer to the variable or field
+// assigned by the expression `lhs` (e.g., `m[k]` for `k = ...`, or `m[s.f]` for `s.f = ...`), since
+// the index may be different before the assignment.
+func (r *RootAssertionNode) releaseIndexNodesReferring(lhs ast.Expr) {
+	var ident *ast.Ident
+	switch lhs := astutil.Unparen(lhs).(type) {
+	case *ast.Ident:
+		ident = lhs
+	case *ast.SelectorExpr:
+		ident = lhs.Sel
+	default:
+		return
+	}
+	obj, ok := r.ObjectOf(ident).(*types.Var)
+	if !ok {
+		return
+	}
+	r.releaseIndexNodes(func(node *indexAssertionNode) bool {
+		refers := false
+		ast.Inspect(node.index, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && r.ObjectOf(id) == obj {
+				refers = true
+			}
+			return !refers
+		})
+		return refers
+	})
+}
+
+// releaseClearedIndexNodes releases the index nodes of the map (or slice) if the expression is a
+// call to the builtin `delete` or `clear` on it, since the values written at any indices before the
+// call may have been removed (we do not distinguish the deleted key from the others as they may be
+// equal at runtime).
+func (r *RootAssertionNode) releaseClearedIndexNodes(expr ast.Expr) {
+	call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return
+	}
+	fun, ok := astutil.Unparen(call.Fun).(*ast.Ident)
+	if !ok || !r.isBuiltIn(fun) || (fun.Name != "delete" && fun.Name != "clear") {
+		return
+	}
+	path, _ := r.ParseExprAsProducer(call.Args[0], false)
+	node, _ := r.lookupPath(path)
+	if node == nil {
+		return
+	}
+	r.releaseIndexNodes(func(index *indexAssertionNode) bool { return index.Parent() == node })
+}
+
+// releaseStoredLoads handles the trusted calls that store a value in their receiver (e.g.,
+// `p.Store(v)` for an `atomic.Pointer[T]`): the tracked loads from the same receiver (e.g.,
+// `p.Load()`) are treated as if they were assigned the stored value, so that a load after a
+// dominating store is no longer considered nilable.
+func (r *RootAssertionNode) releaseStoredLoads(expr ast.Expr) {
+	call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	recv, value, ok := trustedfunc.AsStore(call, r.Pass())
+	if !ok {
+		return
+	}
+	path, _ := r.ParseExprAsProducer(recv, false)
+	node, _ := r.lookupPath(path)
+	if node == nil {
+		return
+	}
+
+	valuePath, valueProducers := r.ParseExprAsProducer(value, false)
+	for i := 0; i < len(node.Children()); i++ {
+		load, ok := node.Children()[i].(*funcAssertionNode)
+		if !ok || !trustedfunc.HasNilableResult(load.decl) {
+			continue
+		}
+		switch {
+		case valuePath != nil:
+			// The stored value is trackable, so move the assertions on the load to it.
+			detachFromParent(load, i)
+			r.LandAtPath(valuePath, load)
+		case len(valueProducers) == 1:
+			r.triggerProductions(load, &annotation.ProduceTrigger{
+				Annotation: valueProducers[0].GetShallow().Annotation,
+				Expr:       value,
+			})
+			detachFromParent(load, i)
+		default:
+			// The stored value is never nil (e.g., `&T{}`).
+			r.triggerProductions(load, &annotation.ProduceTrigger{
+				Annotation: &annotation.ProduceTriggerNever{},
+				Expr:       value,
+			})
+			detachFromParent(load, i)
+		}
+		i--
+	}
+}
+
 func (r *RootAssertionNode) consumeIndexExpr(expr ast.Expr) {
 	t := r.Pass().TypesInfo.Types[expr].Type
 	if util.TypeIsDeeplySlice(t) {
@@ -565,13 +770,13 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 		// Y expression won't be executed if the X expression is true.
 		if expr.Op == token.LAND {
 			for _, e := range [...]ast.Expr{expr.Y, expr.X} {
-				if trueNilCheck, _, isNoop := AddNilCheck(r.Pass(), e); !isNoop {
+				if trueNilCheck, _, isNoop := AddNilCheck(r.Pass(), e, r.functionContext.functionConfig); !isNoop {
 					trueNilCheck(r)
 				}
 			}
 		} else if expr.Op == token.LOR {
 			for _, e := range [...]ast.Expr{expr.Y, expr.X} {
-				if _, falseNilCheck, isNoop := AddNilCheck(r.Pass(), e); !isNoop {
+				if _, falseNilCheck, isNoop := AddNilCheck(r.Pass(), e, r.functionContext.functionConfig); !isNoop {
 					falseNilCheck(r)
 				}
 			}
@@ -579,6 +784,14 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 
 		r.AddComputation(expr.X)
 	case *ast.CallExpr:
+		if r.isFuncValue(expr.Fun) {
+			// calling a nil function value panics, so the called value must be non-nil
+			r.AddConsumption(&annotation.ConsumeTrigger{
+				Annotation: &annotation.FuncValueCall{ConsumeTriggerTautology: &annotation.ConsumeTriggerTautology{}},
+				Expr:       expr.Fun,
+				Guards:     util.NoGuards(),
+			})
+		}
 		r.AddComputation(expr.Fun)
 		exprArgs := r.funcArgsFromCallExpr(expr)
 		var consumeArg func(int, ast.Expr)
@@ -685,6 +898,17 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 					}
 					r.AddConsumption(&consumer)
 
+					// A function passed for a function-typed parameter must accept the arguments
+					// that the called function passes to it, and return the results it expects.
+					r.addCallbackTriggers(fdecl, i, arg)
+
+					// A nil pointer passed for an interface parameter that the callee compares
+					// against nil becomes a typed nil there.
+					if sig := fdecl.Origin().Type().(*types.Signature); i < sig.Params().Len() &&
+						!(sig.Variadic() && i >= sig.Params().Len()-1) && r.isNilCompared(sig.Params().At(i)) {
+						r.consumeIfTypedNil(arg, sig.Params().At(i).Type())
+					}
+
 					// If arg is a deep type, we add a full trigger for it to track its deep nilability.
 					// ```
 					// E.g., func bar(s []*int) {
@@ -729,6 +953,24 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 				// Add Consumptions for struct field params
 				r.addConsumptionsForArgAndReceiverFields(expr, fun)
 			}
+		} else if cb, ok := r.callbackOf(expr.Fun); ok {
+			// here we have found a call to a function-typed parameter of the current function or a
+			// function-typed field (i.e., a callback), so we mark its arguments as consumed by the
+			// callback annotations
+			consumeArg = func(i int, arg ast.Expr) {
+				if expr.Ellipsis != token.NoPos && i == len(expr.Args)-1 {
+					// the unpacking of a variadic argument is not tracked
+					return
+				}
+				r.AddConsumption(&annotation.ConsumeTrigger{
+					Annotation: &annotation.CallbackArgPass{
+						TriggerIfNonNil: &annotation.TriggerIfNonNil{
+							Ann: cb.paramKey(i),
+						}},
+					Expr:   arg,
+					Guards: util.NoGuards(),
+				})
+			}
 		} else {
 			// here we have found either a builtin function like make or new,
 			// or a typecast like int(x) - in either case (at least for now), do nothing to try
@@ -745,6 +987,8 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 			r.AddComputation(arg)
 		}
 	case *ast.CompositeLit:
+		r.consumeConstructorInitializedFields(expr)
+		r.forEachLitField(expr, r.addFieldCallbackTriggers)
 		for _, elt := range expr.Elts {
 			r.AddComputation(elt)
 		}
@@ -783,6 +1027,15 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 		//
 		// - (2) Don't allow the expression X to be nilable by creating a FldAccess (ConsumeTriggerTautology) consumer for it.
 		//       This is default behavior which gets triggered if the above special case is not satisfied.
+		//
+		// If Sel is promoted from embedded fields (e.g., `s.f` where `f` is declared in the embedded field `*Base` of `s`),
+		// X is implicitly dereferenced along the path of embedded fields (i.e., `s.Base.f`). So we create a FldAccess
+		// consumer for each of the embedded fields along the path, and handle the last one (i.e., `s.Base`) as X above.
+		recvExpr := expr.X
+		for _, sel := range r.implicitFieldSelectors(expr) {
+			r.consumeFldAccess(recvExpr, sel.Sel)
+			recvExpr = sel
+		}
 
 		allowNilable := false
 		if funcObj, ok := r.ObjectOf(expr.Sel).(*types.Func); ok { // Check 1:  selector expression is a method invocation
@@ -791,7 +1044,7 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 				conf := r.Pass().ResultOf[config.Analyzer].(*config.Config)
 				if conf.IsPkgInScope(funcObj.Pkg()) { // Check 3: invoked method is in scope
 					// Here, `t` can only be of type interface, struct, or named, of which we only support for struct and named types.
-					if !util.TypeIsDeeplyInterface(r.Pass().TypesInfo.TypeOf(expr.X)) { // Check 4: invoking expression (caller) is of a non-interface type (e.g., struct or named)
+					if !util.TypeIsDeeplyInterface(r.typeOf(recvExpr)) { // Check 4: invoking expression (caller) is of a non-interface type (e.g., struct or named)
 						allowNilable = true
 						// We are in the special case of supporting nilable receivers! Can be nilable depending on declaration annotation/inferred nilability.
 						r.AddConsumption(&annotation.ConsumeTrigger{
@@ -801,7 +1054,7 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 										FuncDecl: funcObj,
 									},
 								}},
-							Expr:   expr.X,
+							Expr:   recvExpr,
 							Guards: util.NoGuards(),
 						})
 					}
@@ -815,11 +1068,7 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 		}
 		if !allowNilable {
 			// We are in the default case -- it's a field/method access! Must be non-nil.
-			r.AddConsumption(&annotation.ConsumeTrigger{
-				Annotation: &annotation.FldAccess{ConsumeTriggerTautology: &annotation.ConsumeTriggerTautology{}, Sel: r.ObjectOf(expr.Sel)},
-				Expr:       expr.X,
-				Guards:     util.NoGuards(),
-			})
+			r.consumeFldAccess(recvExpr, expr.Sel)
 		}
 
 		r.AddComputation(expr.X)
@@ -875,24 +1124,27 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 // is an anonymous function, it will return the fake function declaration created in the
 // function analyzer
 func getFuncIdent(expr *ast.CallExpr, fc *FunctionContext) *ast.Ident {
-	ident := util.FuncIdentFromCallExpr(expr)
-
-	var funcLit *ast.FuncLit
-	// if ident is nil, check if the expr represents a FuncLit node
-	if ident == nil {
-		funcLit, _ = expr.Fun.(*ast.FuncLit)
-	} else {
-		// check if the declaration the ident points to a function literal node
-		funcLit = getFuncLitFromAssignment(ident)
-	}
-
-	if funcLit != nil {
+	if funcLit := FuncLitFromCallExpr(expr); funcLit != nil {
 		if info, ok := fc.funcLitMap[funcLit]; ok {
 			return info.FakeFuncDecl.Name
 		}
 	}
 
-	return ident
+	return util.FuncIdentFromCallExpr(expr)
+}
+
+// FuncLitFromCallExpr returns the function literal called by a call expression, either directly
+// (e.g., `func() {...}()`) or through the variable it is assigned to (e.g., `f := func() {...}`
+// and then `f()`). Otherwise, it returns nil.
+func FuncLitFromCallExpr(expr *ast.CallExpr) *ast.FuncLit {
+	ident := util.FuncIdentFromCallExpr(expr)
+	// if ident is nil, check if the expr represents a FuncLit node
+	if ident == nil {
+		funcLit, _ := expr.Fun.(*ast.FuncLit)
+		return funcLit
+	}
+	// check if the declaration the ident points to a function literal node
+	return getFuncLitFromAssignment(ident)
 }
 
 // getFuncLitFromAssignment if the declaration of the ident is an assignment
@@ -1152,6 +1404,224 @@ func (r *RootAssertionNode) isFunc(ident *ast.Ident) bool {
 	return ok
 }
 
+// isFuncValue checks if the callee `fun` of a call expression is a value of function type (e.g., a
+// variable, parameter, field, or map entry), as opposed to a declared function or method, a function
+// literal, a builtin, or a type conversion. Only the former can be nil at the time of the call.
+func (r *RootAssertionNode) isFuncValue(fun ast.Expr) bool {
+	fun = astutil.Unparen(fun)
+	if tv, ok := r.Pass().TypesInfo.Types[fun]; !ok || tv.IsType() || tv.IsBuiltin() {
+		return false
+	}
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		return !r.isFunc(fun)
+	case *ast.SelectorExpr:
+		return !r.isFunc(fun.Sel)
+	case *ast.IndexExpr:
+		// an index into a generic function is an explicit instantiation (e.g., `foo[int](x)`),
+		// otherwise it is a read of a function value from a slice, array, or map
+		_, isGenericFunc := r.Pass().TypesInfo.TypeOf(fun.X).Underlying().(*types.Signature)
+		return !isGenericFunc
+	case *ast.IndexListExpr, *ast.FuncLit:
+		return false
+	}
+	return true
+}
+
+// callback is a function-typed parameter of the current function or a function-typed struct field
+// (i.e., a callback), whose params and results are annotated by the callback annotation keys.
+type callback struct {
+	sig *types.Signature
+	// paramKey and retKey return the keys of the j-th param and result of the callback.
+	paramKey, retKey func(j int) *annotation.CallbackAnnotationKey
+}
+
+// paramCallback returns the callback of the i-th parameter of fdecl, which must be function-typed.
+func paramCallback(fdecl *types.Func, i int) callback {
+	return callback{
+		sig:      annotation.CallbackSignature(fdecl, i),
+		paramKey: func(j int) *annotation.CallbackAnnotationKey { return annotation.CallbackKeyFromParamNum(fdecl, i, j) },
+		retKey:   func(j int) *annotation.CallbackAnnotationKey { return annotation.CallbackKeyFromRetNum(fdecl, i, j) },
+	}
+}
+
+// fieldCallback returns the callback of the struct field, which must be function-typed.
+func fieldCallback(fld *types.Var) callback {
+	return callback{
+		sig:      annotation.FieldCallbackSignature(fld),
+		paramKey: func(j int) *annotation.CallbackAnnotationKey { return annotation.FieldCallbackKeyFromParamNum(fld, j) },
+		retKey:   func(j int) *annotation.CallbackAnnotationKey { return annotation.FieldCallbackKeyFromRetNum(fld, j) },
+	}
+}
+
+// callbackOf returns the callback that the expression refers to, if it is a function-typed
+// parameter of the current function (e.g., `fn`) or a function-typed struct field (e.g., `s.fn`).
+// For anonymous functions, only their own parameters are considered, but not the fake ones
+// representing the closure variables.
+func (r *RootAssertionNode) callbackOf(expr ast.Expr) (callback, bool) {
+	switch expr := astutil.Unparen(expr).(type) {
+	case *ast.Ident:
+		v, ok := r.ObjectOf(expr).(*types.Var)
+		if !ok {
+			return callback{}, false
+		}
+		funcObj := r.FuncObj()
+		params := funcObj.Type().(*types.Signature).Params()
+		numParams := params.Len()
+		if funcLit := r.functionContext.funcLit; funcLit != nil {
+			numParams = r.Pass().TypesInfo.TypeOf(funcLit).(*types.Signature).Params().Len()
+		}
+		for i := 0; i < numParams; i++ {
+			if params.At(i) == v && annotation.CallbackSignature(funcObj, i) != nil {
+				return paramCallback(funcObj, i), true
+			}
+		}
+	case *ast.SelectorExpr:
+		sel, ok := r.Pass().TypesInfo.Selections[expr]
+		if !ok || sel.Kind() != types.FieldVal {
+			return callback{}, false
+		}
+		fld, ok := sel.Obj().(*types.Var)
+		if !ok {
+			return callback{}, false
+		}
+		conf := r.Pass().ResultOf[config.Analyzer].(*config.Config)
+		if annotation.FieldCallbackSignature(fld) != nil && conf.IsPkgInScope(fld.Pkg()) {
+			return fieldCallback(fld), true
+		}
+	}
+	return callback{}, false
+}
+
+// addCallbackTriggers adds the full triggers matching the annotations of the params and results of
+// the i-th parameter of fdecl, if it is function-typed (i.e., a callback), with the ones of the
+// function passed as the argument `arg` for it (see matchCallback).
+func (r *RootAssertionNode) addCallbackTriggers(fdecl *types.Func, i int, arg ast.Expr) {
+	conf := r.Pass().ResultOf[config.Analyzer].(*config.Config)
+	if annotation.CallbackSignature(fdecl, i) == nil || !conf.IsPkgInScope(fdecl.Pkg()) {
+		return
+	}
+	r.matchCallback(paramCallback(fdecl, i), arg)
+}
+
+// addFieldCallbackTriggers adds the full triggers matching the annotations of the params and
+// results of the struct field, if it is function-typed (i.e., a callback), with the ones of the
+// function `value` assigned to it (e.g., `S{fn: f}` or `s.fn = f`, see matchCallback).
+func (r *RootAssertionNode) addFieldCallbackTriggers(fld *types.Var, value ast.Expr) {
+	conf := r.Pass().ResultOf[config.Analyzer].(*config.Config)
+	if annotation.FieldCallbackSignature(fld) == nil || !conf.IsPkgInScope(fld.Pkg()) {
+		return
+	}
+	r.matchCallback(fieldCallback(fld), value)
+}
+
+// matchCallback adds the full triggers matching the annotations of the params and results of the
+// callback with the ones of the function `arg` passed or assigned for it. The function can be a
+// declared function or method, an anonymous function (if the support is enabled), or another
+// callback (i.e., a function-typed parameter of the current function or a function-typed field).
+func (r *RootAssertionNode) matchCallback(cb callback, arg ast.Expr) {
+	conf := r.Pass().ResultOf[config.Analyzer].(*config.Config)
+	var paramKey, retKey func(j int) annotation.Key
+	setFuncKeys := func(funcObj *types.Func) {
+		if funcObj == nil || !conf.IsPkgInScope(funcObj.Pkg()) {
+			return
+		}
+		paramKey = func(j int) annotation.Key { return annotation.ParamKeyFromArgNum(funcObj, j) }
+		retKey = func(j int) annotation.Key { return annotation.RetKeyFromRetNum(funcObj, j) }
+	}
+	if other, ok := r.callbackOf(arg); ok {
+		paramKey = func(j int) annotation.Key { return other.paramKey(j) }
+		retKey = func(j int) annotation.Key { return other.retKey(j) }
+	} else {
+		switch arg := astutil.Unparen(arg).(type) {
+		case *ast.FuncLit:
+			if info, ok := r.functionContext.funcLitMap[arg]; ok && r.functionContext.functionConfig.EnableAnonymousFunc {
+				setFuncKeys(info.FakeFuncObj)
+			}
+		case *ast.Ident:
+			funcObj, _ := r.ObjectOf(arg).(*types.Func)
+			setFuncKeys(funcObj)
+		case *ast.SelectorExpr:
+			funcObj, _ := r.ObjectOf(arg.Sel).(*types.Func)
+			setFuncKeys(funcObj)
+			// For a method expression (e.g., `(*T).M`), param 0 of the callback is the receiver of
+			// the method, and the rest are the params of the method shifted by one.
+			if sel, ok := r.Pass().TypesInfo.Selections[arg]; ok && sel.Kind() == types.MethodExpr && paramKey != nil {
+				methodParamKey := paramKey
+				paramKey = func(j int) annotation.Key {
+					if j == 0 {
+						return &annotation.RecvAnnotationKey{FuncDecl: funcObj}
+					}
+					return methodParamKey(j - 1)
+				}
+			}
+		}
+	}
+	if paramKey == nil {
+		return
+	}
+
+	for j := 0; j < cb.sig.Params().Len(); j++ {
+		r.AddNewTriggers(annotation.FullTriggerForCallbackParamFlow(cb.paramKey(j), paramKey(j), arg))
+	}
+	for j := 0; j < cb.sig.Results().Len(); j++ {
+		r.AddNewTriggers(annotation.FullTriggerForCallbackResultFlow(cb.retKey(j), retKey(j), arg))
+	}
+}
+
+// implicitFieldSelectors returns the artificial selector expressions for the embedded fields that
+// are implicitly selected by the selector expression, e.g., `s.Base` and `s.Base.Inner` for `s.f`
+// where `f` is promoted from the embedded field `Inner` of the embedded field `Base` of `s`. It
+// returns nil if the selector is not promoted.
+func (r *RootAssertionNode) implicitFieldSelectors(expr *ast.SelectorExpr) []*ast.SelectorExpr {
+	selection, ok := r.Pass().TypesInfo.Selections[expr]
+	if !ok || selection.Kind() == types.MethodExpr || len(selection.Index()) < 2 {
+		return nil
+	}
+
+	var sels []*ast.SelectorExpr
+	var x ast.Expr = expr.X
+	t := selection.Recv()
+	for _, index := range selection.Index()[:len(selection.Index())-1] {
+		if ptr, ok := t.Underlying().(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		structType, ok := t.Underlying().(*types.Struct)
+		if !ok || index >= structType.NumFields() {
+			return sels
+		}
+		fld := structType.Field(index)
+		sel := r.getSelectorExpr(fld, x)
+		sels = append(sels, sel)
+		x, t = sel, fld.Type()
+	}
+	return sels
+}
+
+// typeOf is the same as [types.Info.TypeOf], but it also resolves the types of the artificial
+// selector expressions of fields (see getSelectorExpr) that are unknown to the type checker.
+func (r *RootAssertionNode) typeOf(expr ast.Expr) types.Type {
+	if t := r.Pass().TypesInfo.TypeOf(expr); t != nil {
+		return t
+	}
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if fld, ok := r.ObjectOf(sel.Sel).(*types.Var); ok {
+			return fld.Type()
+		}
+	}
+	return nil
+}
+
+// consumeFldAccess adds a FldAccess consumer for the receiver expression of a field or method
+// access, which must be nonnil.
+func (r *RootAssertionNode) consumeFldAccess(recv ast.Expr, sel *ast.Ident) {
+	r.AddConsumption(&annotation.ConsumeTrigger{
+		Annotation: &annotation.FldAccess{ConsumeTriggerTautology: &annotation.ConsumeTriggerTautology{}, Sel: r.ObjectOf(sel)},
+		Expr:       recv,
+		Guards:     util.NoGuards(),
+	})
+}
+
 // checks if this expression is an instance of types.Var
 func (r *RootAssertionNode) isVariable(ident *ast.Ident) bool {
 	_, ok := r.ObjectOf(ident).(*types.Var)
@@ -1286,6 +1756,10 @@ func (r *RootAssertionNode) eqStable(left, right ast.Expr) bool {
 				(r.isPkgName(left) && r.isPkgName(right)) {
 				return left.Name == right.Name
 			}
+			// type names (e.g., of conversions) are equal if they refer to the same declaration
+			if r.isTypeName(left) && r.isTypeName(right) {
+				return r.ObjectOf(left) == r.ObjectOf(right)
+			}
 			rightVarObj, rightOk := r.ObjectOf(right).(*types.Var)
 			leftVarObj, leftOk := r.ObjectOf(left).(*types.Var)
 
@@ -176,7 +176,10 @@ func inverseToken(t token.Token) token.Token {
 //
 // For better performance by the caller, it also returns a boolean flag `isNoop` indicating whether
 // the returned function is a no-op
-func AddNilCheck(pass *analysis.Pass, expr ast.Expr) (trueCheck, falseCheck RootFunc, isNoop bool) {
+//
+// If strict mode is enabled in `functionConfig`, the optimistic interpretations of length checks
+// (see below) are disabled.
+func AddNilCheck(pass *analysis.Pass, expr ast.Expr, functionConfig FunctionConfig) (trueCheck, falseCheck RootFunc, isNoop bool) {
 	noop := func(_ *RootAssertionNode) {}
 
 	expr = astutil.Unparen(expr)
@@ -189,7 +192,7 @@ func AddNilCheck(pass *analysis.Pass, expr ast.Expr) (trueCheck, falseCheck Root
 		// negative nil check for the true branch. But since it is preceded with a negation (!), the below code
 		// interchanges the true and false branches, and returns trueCheck: noop, falseCheck: produceNegativeNilCheck,
 		// implying a negative nil check for the false branch.
-		trueNilCheck, falseNilCheck, isNoop := AddNilCheck(pass, e.X)
+		trueNilCheck, falseNilCheck, isNoop := AddNilCheck(pass, e.X, functionConfig)
 		return falseNilCheck, trueNilCheck, isNoop
 	}
 	binExpr, ok := expr.(*ast.BinaryExpr)
@@ -244,8 +247,9 @@ func AddNilCheck(pass *analysis.Pass, expr ast.Expr) (trueCheck, falseCheck Root
 	// are positive - see the uses of this function below to admit nonliteral ints everywhere
 	// positive ints are matched on
 	// TODO - evaluate the unsoundness of this assumption in practice more completely
+	// In strict mode, we do not make this assumption.
 	isNonLiteralInt := func(expr ast.Expr) bool {
-		if isLiteralInt(expr) {
+		if functionConfig.StrictMode || isLiteralInt(expr) {
 			return false
 		}
 		if t, ok := pass.TypesInfo.Types[expr].Type.(*types.Basic); ok {
@@ -297,8 +301,12 @@ func AddNilCheck(pass *analysis.Pass, expr ast.Expr) (trueCheck, falseCheck Root
 			// it
 			// TODO - evaluate the impact of this unsound assumption, and maybe switch to treating
 			// it as a contract that only generates non-nil for one side when the other is checked
+			// In strict mode, we do not admit this assumption.
 			op: token.EQL,
 			matcher: func(x, y ast.Expr) (RootFunc, RootFunc, bool) {
+				if functionConfig.StrictMode {
+					return noop, noop, true
+				}
 				xLenArg, xIsLen := asLenCall(x)
 				yLenArg, yIsLen := asLenCall(y)
 
@@ -605,3 +613,50 @@ func FilterTriggersForErrorReturn(
 	}
 	return filteredTriggers, deletedTriggers
 }
+
+// CollectNilComparedVars collects the interface-typed variables (including parameters) in the given
+// files that are compared against nil (e.g., `if err != nil`), along with the named results of type
+// `error`, which are conventionally compared against nil by the callers. A nil pointer stored in such
+// a variable makes the comparison misbehave (the interface holds a "typed nil" and is itself non-nil),
+// hence we check that only non-nil pointers flow into them.
+func CollectNilComparedVars(pass *analysis.Pass, files []*ast.File) map[*types.Var]bool {
+	vars := make(map[*types.Var]bool)
+	addIfInterfaceVar := func(expr ast.Expr) {
+		ident, ok := astutil.Unparen(expr).(*ast.Ident)
+		if !ok {
+			return
+		}
+		if v, ok := pass.TypesInfo.ObjectOf(ident).(*types.Var); ok && types.IsInterface(v.Type()) {
+			vars[v] = true
+		}
+	}
+
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.BinaryExpr:
+				if node.Op != token.EQL && node.Op != token.NEQ {
+					return true
+				}
+				if pass.TypesInfo.Types[node.Y].IsNil() {
+					addIfInterfaceVar(node.X)
+				} else if pass.TypesInfo.Types[node.X].IsNil() {
+					addIfInterfaceVar(node.Y)
+				}
+			case *ast.FuncType:
+				if node.Results == nil {
+					return true
+				}
+				for _, field := range node.Results.List {
+					for _, name := range field.Names {
+						if v, ok := pass.TypesInfo.ObjectOf(name).(*types.Var); ok && types.Identical(v.Type(), util.ErrorType) {
+							vars[v] = true
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+	return vars
+}
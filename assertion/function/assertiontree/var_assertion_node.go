@@ -52,10 +52,7 @@ func (v *varAssertionNode) DefaultTrigger() annotation.ProducingAnnotationTrigge
 		}
 	}
 	if annotation.VarIsGlobal(v.decl) {
-		return &annotation.GlobalVarRead{
-			TriggerIfNilable: &annotation.TriggerIfNilable{
-				Ann: &annotation.GlobalVarAnnotationKey{
-					VarDecl: v.decl}}}
+		return v.Root().functionContext.globalVarProducer(v.decl)
 	}
 
 	// By process of elimination we know that here `v` is a local variable
@@ -0,0 +1,135 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"context"
+	"runtime/metrics"
+	"sync"
+	"time"
+)
+
+// _budgetCheckInterval is the interval between two checks of the heap size against the budget.
+const _budgetCheckInterval = 10 * time.Millisecond
+
+// _heapMetric is the runtime metric for the heap size, i.e., the bytes occupied by the live and
+// not-yet-swept objects.
+const _heapMetric = "/memory/classes/heap/objects:bytes"
+
+// budgetMonitor enforces a soft budget on the heap size during the analyses of the functions: while
+// the budget is exceeded, it cancels the longest-running analysis at every check, which happens
+// periodically and whenever an analysis starts. Note that the heap
+// is shared by the entire process (e.g., other packages being analyzed concurrently), so the budget
+// is a best-effort limit rather than a precise accounting of the memory used by each function.
+type budgetMonitor struct {
+	// budget is the soft limit on the heap size in bytes.
+	budget uint64
+	// heapSize returns the current heap size, which can be replaced in tests.
+	heapSize func() uint64
+
+	mu sync.Mutex
+	// running maps the indices of the running analyses to their start times and cancel functions.
+	running map[int]runningFunc
+	// cancelled is the set of indices of the analyses cancelled by the monitor.
+	cancelled map[int]bool
+}
+
+// runningFunc is an analysis of a function being run.
+type runningFunc struct {
+	start  time.Time
+	cancel context.CancelFunc
+}
+
+func newBudgetMonitor(budget uint64) *budgetMonitor {
+	return &budgetMonitor{
+		budget:    budget,
+		heapSize:  readHeapSize,
+		running:   make(map[int]runningFunc),
+		cancelled: make(map[int]bool),
+	}
+}
+
+// readHeapSize reads the current heap size from the runtime metrics.
+func readHeapSize() uint64 {
+	sample := []metrics.Sample{{Name: _heapMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// start registers the analysis of the function at the index, and returns the context for it along
+// with a function to unregister it when it is done. The budget is checked right away, so an
+// analysis started beyond the budget may be cancelled before it makes any progress if it is the
+// only one running.
+func (m *budgetMonitor) start(ctx context.Context, index int) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	m.mu.Lock()
+	m.running[index] = runningFunc{start: time.Now(), cancel: cancel}
+	m.mu.Unlock()
+	m.check()
+	return ctx, func() {
+		m.mu.Lock()
+		delete(m.running, index)
+		m.mu.Unlock()
+		cancel()
+	}
+}
+
+// check cancels the longest-running analysis if the heap size exceeds the budget.
+func (m *budgetMonitor) check() {
+	if m.heapSize() <= m.budget {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldest := -1
+	for index, f := range m.running {
+		// Break the ties by the indices for determinism.
+		if oldest == -1 || f.start.Before(m.running[oldest].start) ||
+			(f.start.Equal(m.running[oldest].start) && index < oldest) {
+			oldest = index
+		}
+	}
+	if oldest == -1 {
+		return
+	}
+	m.running[oldest].cancel()
+	delete(m.running, oldest)
+	m.cancelled[oldest] = true
+}
+
+// run checks the budget periodically until the context is done.
+func (m *budgetMonitor) run(ctx context.Context) {
+	ticker := time.NewTicker(_budgetCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check()
+		}
+	}
+}
+
+// isCancelled returns true iff the analysis of the function at the index was cancelled by the
+// monitor.
+func (m *budgetMonitor) isCancelled(index int) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cancelled[index]
+}
@@ -0,0 +1,63 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package function
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetMonitor(t *testing.T) {
+	t.Parallel()
+
+	heap := uint64(100)
+	m := newBudgetMonitor(100)
+	m.heapSize = func() uint64 { return heap }
+
+	// Within the budget, nothing is cancelled, neither at the starts nor at the checks.
+	ctx0, done0 := m.start(context.Background(), 0)
+	defer done0()
+	ctx1, done1 := m.start(context.Background(), 1)
+	defer done1()
+	m.check()
+	require.NoError(t, ctx0.Err())
+	require.NoError(t, ctx1.Err())
+
+	// Beyond the budget, the longest-running analysis is cancelled at every check.
+	heap = 101
+	m.check()
+	require.ErrorIs(t, ctx0.Err(), context.Canceled)
+	require.NoError(t, ctx1.Err())
+	require.True(t, m.isCancelled(0))
+	require.False(t, m.isCancelled(1))
+
+	// The analyses that are done are no longer cancelled by the monitor.
+	done1()
+	m.check()
+	require.False(t, m.isCancelled(1))
+
+	// Beyond the budget, an analysis started alone is cancelled right away.
+	ctx2, done2 := m.start(context.Background(), 2)
+	defer done2()
+	require.ErrorIs(t, ctx2.Err(), context.Canceled)
+	require.True(t, m.isCancelled(2))
+
+	// The monitor stops when the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.run(ctx)
+}
@@ -26,6 +26,7 @@ import (
 	"runtime/debug"
 	"sync"
 
+	"go.uber.org/nilaway/assertion/anonymousfunc"
 	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/util"
 	"go.uber.org/nilaway/util/analysishelper"
@@ -43,7 +44,7 @@ var Analyzer = &analysis.Analyzer{
 	Doc:        _doc,
 	Run:        analysishelper.WrapRun(run),
 	ResultType: reflect.TypeOf((*analysishelper.Result[Map])(nil)),
-	Requires:   []*analysis.Analyzer{config.Analyzer, buildssa.Analyzer},
+	Requires:   []*analysis.Analyzer{config.Analyzer, buildssa.Analyzer, anonymousfunc.Analyzer},
 }
 
 func run(pass *analysis.Pass) (Map, error) {
@@ -100,8 +101,6 @@ func collectFunctionContracts(pass *analysis.Pass) (Map, error) {
 			funcDecl, ok := decl.(*ast.FuncDecl)
 			if !ok {
 				// Ignore any non-function declaration
-				// TODO: If we want to support contracts for anonymous functions (function
-				//  literals) in the future, then we need to handle more types here.
 				continue
 			}
 			funcObj := pass.TypesInfo.ObjectOf(funcDecl.Name).(*types.Func)
@@ -169,6 +168,19 @@ func collectFunctionContracts(pass *analysis.Pass) (Map, error) {
 		}
 	}
 
+	// Parse the contracts of the anonymous functions (function literals) from their doc comments
+	// (see anonymousfunc.FuncLitInfo) for their fake function declarations. Note that we do not
+	// infer contracts for anonymous functions.
+	funcLitResult := pass.ResultOf[anonymousfunc.Analyzer].(*analysishelper.Result[map[*ast.FuncLit]*anonymousfunc.FuncLitInfo])
+	if funcLitResult.Err != nil {
+		return nil, funcLitResult.Err
+	}
+	for _, info := range funcLitResult.Res {
+		if parsedContracts := parseContracts(info.Doc); len(parsedContracts) != 0 {
+			m[info.FakeFuncObj] = parsedContracts
+		}
+	}
+
 	// Spawn another goroutine that will close the channel when all analyses are done. This makes
 	// sure the channel receive logic in the main thread (below) can properly terminate.
 	go func() {
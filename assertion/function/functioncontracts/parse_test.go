@@ -0,0 +1,54 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package functioncontracts
+
+import (
+	"go/ast"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// FuzzParseContracts checks that parsing arbitrary comments never panics (e.g., on unexpected
+// contract values), is deterministic, and only produces well-formed contracts.
+func FuzzParseContracts(f *testing.F) {
+	for _, text := range []string{
+		"// contract(nonnil -> nonnil)",
+		"// contract(_, nonnil -> true) contract(_,_->false)",
+		"//contract( nonnil ->nonnil )",
+		"// contract(nonnil -> nonnil) trailing",
+		"// contract(maybe -> nonnil)",
+		"/* contract(nonnil -> nonnil) */",
+	} {
+		f.Add(text)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		doc := &ast.CommentGroup{List: []*ast.Comment{{Text: text}}}
+		contracts := parseContracts(doc)
+		require.Equal(t, contracts, parseContracts(doc))
+		if len(contracts) > 0 {
+			require.True(t, strings.HasPrefix(strings.TrimSpace(text), "//"))
+		}
+		for _, c := range contracts {
+			require.NotEmpty(t, c.Ins)
+			require.NotEmpty(t, c.Outs)
+			for _, v := range append(c.Ins, c.Outs...) {
+				require.Contains(t, []ContractVal{NonNil, False, True, Any}, v)
+			}
+		}
+	})
+}
@@ -0,0 +1,95 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nonnilresults implements a sub-analyzer to read the `always-nonnil` and `error-nonnil`
+// annotations of functions. By default, all but the last result of an error-returning (or
+// ok-returning) function are guarded, i.e., the callers must check the error (or ok) result before
+// using them. These annotations allow some results to escape from the guarding, e.g., for
+// constructors that always return a usable value even with an error.
+//
+// The annotations are written as special comments before function declarations (e.g.,
+// `// always-nonnil(result 0)`), or in stub files (see parseStubFile) for functions whose sources
+// we do not own. A few standard library functions are annotated by default (see builtinResults).
+package nonnilresults
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+
+	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/util/analysishelper"
+	"golang.org/x/tools/go/analysis"
+)
+
+const _doc = "Read the always-nonnil and error-nonnil annotations of the functions in this package " +
+	"and its dependencies, and in the configured stub files, returning the results."
+
+// Analyzer here is the analyzer that reads the always-nonnil and error-nonnil annotations. It
+// returns the map of annotated results of all functions visible from the package.
+var Analyzer = &analysis.Analyzer{
+	Name:       "nilaway_nonnil_results_analyzer",
+	Doc:        _doc,
+	Run:        analysishelper.WrapRun(run),
+	FactTypes:  []analysis.Fact{new(Fact)},
+	ResultType: reflect.TypeOf((*analysishelper.Result[Map])(nil)),
+	Requires:   []*analysis.Analyzer{config.Analyzer},
+}
+
+func run(pass *analysis.Pass) (Map, error) {
+	conf := pass.ResultOf[config.Analyzer].(*config.Config)
+
+	if !conf.IsPkgInScope(pass.Pkg) {
+		return Map{}, nil
+	}
+
+	m := Map{}
+	m.merge(builtinResults)
+
+	// Import the annotations of the functions declared in upstream packages.
+	for _, f := range pass.AllPackageFacts() {
+		if fact, ok := f.Fact.(*Fact); ok {
+			m.merge(fact.Results)
+		}
+	}
+
+	// Read the annotations in the stub files, which may complement the upstream ones.
+	for _, path := range conf.StubFiles {
+		if err := parseStubFile(m, path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read the annotations of the functions declared in this package, and export them for the
+	// downstream packages.
+	current := Map{}
+	for _, file := range pass.Files {
+		if !conf.IsFileInScope(file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			parseDoc(current, funcDecl.Doc, pass.TypesInfo.ObjectOf(funcDecl.Name).(*types.Func))
+		}
+	}
+	if len(current) > 0 {
+		pass.ExportPackageFact(&Fact{Results: current})
+		m.merge(current)
+	}
+
+	return m, nil
+}
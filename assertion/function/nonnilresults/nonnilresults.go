@@ -0,0 +1,100 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nonnilresults
+
+import (
+	"go/types"
+
+	"go.uber.org/nilaway/util"
+)
+
+// Kind represents the kind of annotation that exempts a result of an error-returning or
+// ok-returning function from being guarded by its last result.
+type Kind uint8
+
+const (
+	// AlwaysNonNil has keyword "always-nonnil": the result is nonnil regardless of the value of
+	// the last (error or ok) result.
+	AlwaysNonNil Kind = iota + 1
+	// ErrorNonNil has keyword "error-nonnil": the result is nonnil even if the last (error) result
+	// is nonnil. It has no effect on ok-returning functions.
+	ErrorNonNil
+)
+
+// Map stores the mappings from the full names of functions (see types.Func.FullName) to the
+// annotated results of the functions, keyed by the result index. We key the map by names instead
+// of *types.Func so that it can be passed across packages as a fact, and so that the annotations
+// can be written in stub files for functions whose sources we do not own.
+type Map map[string]map[int]Kind
+
+// builtinResults records the results of the standard library functions that escape from the
+// guarding by their last (ok) result. For example, `sync.Map.LoadOrStore` returns the given value
+// if no value was loaded, so its first result is nonnil regardless of the `loaded` result.
+//
+// Note that the annotations are keyed by the functions only, so they cannot depend on the
+// arguments at the call sites. This is a known limitation (i.e., a source of false negatives) for
+// `sync.Map.LoadOrStore`, whose first result is nil if nil is stored (e.g., `m.LoadOrStore(k,
+// nil)`), but we still assume it nonnil since storing nil values is rare in practice.
+var builtinResults = Map{
+	"(*sync.Map).LoadOrStore": {0: AlwaysNonNil},
+}
+
+// IsUnguarded returns true iff the i-th result of the given function is annotated to escape from
+// the guarding by its last (error or ok) result.
+func (m Map) IsUnguarded(funcObj *types.Func, i int) bool {
+	if len(m) == 0 || funcObj == nil {
+		return false
+	}
+
+	kind, ok := m[funcObj.Origin().FullName()][i]
+	if !ok {
+		return false
+	}
+	switch kind {
+	case AlwaysNonNil:
+		return true
+	case ErrorNonNil:
+		return util.FuncIsErrReturning(funcObj)
+	default:
+		return false
+	}
+}
+
+// add records the annotation of the i-th result of the function with the given full name.
+func (m Map) add(name string, i int, kind Kind) {
+	if _, ok := m[name]; !ok {
+		m[name] = make(map[int]Kind)
+	}
+	m[name][i] = kind
+}
+
+// merge copies all annotations in the other map into this map. The inner maps are not shared
+// since the other map may come from an (immutable) fact.
+func (m Map) merge(other Map) {
+	for name, results := range other {
+		for i, kind := range results {
+			m.add(name, i, kind)
+		}
+	}
+}
+
+// Fact is the package fact that passes the annotated results of the functions declared in a
+// package to its downstream packages.
+type Fact struct {
+	Results Map
+}
+
+// AFact enables use of the facts passing mechanism in Go's analysis framework
+func (*Fact) AFact() {}
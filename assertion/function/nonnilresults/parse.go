@@ -0,0 +1,138 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nonnilresults
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const _sep = ","
+const _alwaysNonNilKeyword = "always-nonnil"
+const _errorNonNilKeyword = "error-nonnil"
+const _resultPrefix = "result "
+
+// _annotationRE matches the annotations in a line, e.g., `always-nonnil(result 0, conn)`. It
+// captures the keyword and the list of results, each of which is written either as `result N` or
+// as the name of the result.
+var _annotationRE = regexp.MustCompile(fmt.Sprintf(
+	"(%s|%s)\\(\\s*((?:result\\s+[0-9]+|[a-zA-Z_][a-zA-Z0-9_]*)(?:\\s*%s\\s*(?:result\\s+[0-9]+|[a-zA-Z_][a-zA-Z0-9_]*))*)\\s*\\)",
+	_alwaysNonNilKeyword, _errorNonNilKeyword, _sep))
+
+// annotatedResult is a result reference read from an annotation along with its kind.
+type annotatedResult struct {
+	// ref is either `result N` or the name of the result.
+	ref  string
+	kind Kind
+}
+
+// parseLine parses all annotations in a single line of text.
+func parseLine(text string) []annotatedResult {
+	var results []annotatedResult
+	for _, matching := range _annotationRE.FindAllStringSubmatch(text, -1) {
+		kind := AlwaysNonNil
+		if matching[1] == _errorNonNilKeyword {
+			kind = ErrorNonNil
+		}
+		for _, ref := range strings.Split(matching[2], _sep) {
+			results = append(results, annotatedResult{ref: strings.Join(strings.Fields(ref), " "), kind: kind})
+		}
+	}
+	return results
+}
+
+// resultIndex resolves a result reference to its index in the signature. It returns false if the
+// reference does not name a non-final result of the signature (the final result is the guard
+// itself, hence it can never be exempted).
+func resultIndex(ref string, sig *types.Signature) (int, bool) {
+	numResults := sig.Results().Len()
+	if strings.HasPrefix(ref, _resultPrefix) {
+		i, err := strconv.Atoi(strings.TrimPrefix(ref, _resultPrefix))
+		if err != nil || i < 0 || i >= numResults-1 {
+			return 0, false
+		}
+		return i, true
+	}
+	for i := 0; i < numResults-1; i++ {
+		if sig.Results().At(i).Name() == ref {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// parseDoc parses the annotations from the doc comment of a function and records them in the map.
+func parseDoc(m Map, doc *ast.CommentGroup, funcObj *types.Func) {
+	if doc == nil {
+		return
+	}
+	sig := funcObj.Type().(*types.Signature)
+	for _, comment := range doc.List {
+		for _, r := range parseLine(comment.Text) {
+			if i, ok := resultIndex(r.ref, sig); ok {
+				m.add(funcObj.FullName(), i, r.kind)
+			}
+		}
+	}
+}
+
+// parseStubFile parses a stub file that annotates functions whose sources we do not own (e.g.,
+// third-party or generated code). Each line of a stub file consists of the full name of a
+// function (see types.Func.FullName) followed by its annotations, and lines starting with "#" are
+// comments. Since the signatures are unavailable here, results can only be referred to by their
+// indices. For example:
+//
+//	# comments are allowed
+//	example.com/client.New always-nonnil(result 0)
+//	(*example.com/pool.Pool).Get error-nonnil(result 0)
+func parseStubFile(m Map, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open stub file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, rest, _ := strings.Cut(line, " ")
+		annotated := parseLine(rest)
+		if len(annotated) == 0 {
+			return fmt.Errorf("%s:%d: no annotations found for %q", path, lineNum, name)
+		}
+		for _, r := range annotated {
+			i, err := strconv.Atoi(strings.TrimPrefix(r.ref, _resultPrefix))
+			if !strings.HasPrefix(r.ref, _resultPrefix) || err != nil {
+				return fmt.Errorf("%s:%d: results must be referred to as `result N` in stub files, got %q",
+					path, lineNum, r.ref)
+			}
+			m.add(name, i, r.kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read stub file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nonnilresults
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/nilaway/util/analysishelper"
+)
+
+func TestAnalyzer(t *testing.T) {
+	t.Parallel()
+
+	// Intentionally give a nil pass variable to trigger a panic, but we should recover from it
+	// and convert it to an error via the result struct.
+	r, err := Analyzer.Run(nil /* pass */)
+	require.NoError(t, err)
+	require.ErrorContains(t, r.(*analysishelper.Result[Map]).Err, "INTERNAL PANIC")
+}
+
+func TestParseLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want []annotatedResult
+	}{
+		{name: "no annotation", text: "// nonnil(result 0)", want: nil},
+		{name: "always-nonnil", text: "// always-nonnil(result 0)", want: []annotatedResult{{ref: "result 0", kind: AlwaysNonNil}}},
+		{name: "error-nonnil", text: "// error-nonnil(conn)", want: []annotatedResult{{ref: "conn", kind: ErrorNonNil}}},
+		{
+			name: "multiple",
+			text: "// always-nonnil( result  0 , b) error-nonnil(c)",
+			want: []annotatedResult{
+				{ref: "result 0", kind: AlwaysNonNil},
+				{ref: "b", kind: AlwaysNonNil},
+				{ref: "c", kind: ErrorNonNil},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, parseLine(tt.text))
+		})
+	}
+}
+
+// FuzzParseLine checks that parsing arbitrary lines never panics, is deterministic, and only
+// produces well-formed result references.
+func FuzzParseLine(f *testing.F) {
+	for _, text := range []string{
+		"// always-nonnil(result 0)",
+		"// always-nonnil( result  0 , b) error-nonnil(c)",
+		"// error-nonnil(result 99999999999999999999)",
+		"// always-nonnil()",
+		"example.com/client.New always-nonnil(result 0, conn",
+	} {
+		f.Add(text)
+	}
+
+	f.Fuzz(func(t *testing.T, text string) {
+		results := parseLine(text)
+		require.Equal(t, results, parseLine(text))
+		for _, r := range results {
+			require.NotEmpty(t, r.ref)
+			require.Contains(t, []Kind{AlwaysNonNil, ErrorNonNil}, r.kind)
+		}
+	})
+}
+
+func TestParseStubFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.txt")
+	require.NoError(t, os.WriteFile(valid, []byte(`
+# comment
+example.com/client.New always-nonnil(result 0)
+(*example.com/pool.Pool).Get error-nonnil(result 0, result 1)
+`), 0o600))
+	m := Map{}
+	require.NoError(t, parseStubFile(m, valid))
+	require.Equal(t, Map{
+		"example.com/client.New":       {0: AlwaysNonNil},
+		"(*example.com/pool.Pool).Get": {0: ErrorNonNil, 1: ErrorNonNil},
+	}, m)
+
+	named := filepath.Join(dir, "named.txt")
+	require.NoError(t, os.WriteFile(named, []byte("example.com/client.New always-nonnil(c)\n"), 0o600))
+	require.ErrorContains(t, parseStubFile(Map{}, named), "named.txt:1")
+
+	missing := filepath.Join(dir, "missing.txt")
+	require.ErrorContains(t, parseStubFile(Map{}, missing), "open stub file")
+}
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
@@ -71,14 +71,23 @@ type trustedFuncSig struct {
 // the enclosing package or struct path.
 func (t *trustedFuncSig) match(call *ast.CallExpr, pass *analysis.Pass) bool {
 	sel, ok := call.Fun.(*ast.SelectorExpr)
-	if !ok || !t.funcNameRegex.MatchString(sel.Sel.Name) {
+	if !ok {
+		return false
+	}
+	funcObj, ok := pass.TypesInfo.ObjectOf(sel.Sel).(*types.Func)
+	return ok && t.matchFunc(funcObj)
+}
+
+// matchFunc checks if a given function matches with a trusted function's signature. See match.
+func (t *trustedFuncSig) matchFunc(funcObj *types.Func) bool {
+	if !t.funcNameRegex.MatchString(funcObj.Name()) {
 		return false
 	}
 
-	// Match fully qualified path of the call expression with the expected path specified in `t`
+	// Match fully qualified path of the function with the expected path specified in `t`
 	// if function, match enclosing "<pkg path>". E.g., for `assert.Error(err)`, path = github.com/stretchr/testify/assert
 	// if method, match with "<pkg path>.<struct name>". E.g., for `u.Require().Error(err)`, path = github.com/stretchr/testify/require.Assertions
-	if funcObj, ok := pass.TypesInfo.ObjectOf(sel.Sel).(*types.Func); ok && funcObj.Pkg() != nil {
+	if funcObj.Pkg() != nil {
 		recv := funcObj.Type().(*types.Signature).Recv()
 		path := funcObj.Pkg().Path()
 
@@ -475,3 +484,83 @@ var trustedFuncs = map[trustedFuncSig]trustedFuncAction{
 		funcNameRegex:  regexp.MustCompile(`^(Empty(f)?|NotEmpty(f)?)$`),
 	}: {action: requireZeroComparators, argIndex: 0},
 }
+
+// nilableResultFuncs defines the trusted functions whose only result may be nil even though the
+// function is not annotated, e.g., `atomic.Pointer[T].Load()` and `atomic.Value.Load()` return nil
+// before the first `Store`.
+var nilableResultFuncs = []trustedFuncSig{
+	{
+		kind:           _method,
+		enclosingRegex: regexp.MustCompile(`^sync/atomic\.(Pointer|Value)$`),
+		funcNameRegex:  regexp.MustCompile(`^Load$`),
+	},
+}
+
+// storeFuncs defines the trusted functions that store their only argument in their receiver, to be
+// returned by the later calls to the function in nilableResultFuncs on the same receiver, e.g.,
+// `p.Store(v)` for `p.Load()`.
+var storeFuncs = []trustedFuncSig{
+	{
+		kind:           _method,
+		enclosingRegex: regexp.MustCompile(`^sync/atomic\.(Pointer|Value)$`),
+		funcNameRegex:  regexp.MustCompile(`^Store$`),
+	},
+}
+
+// HasNilableResult returns true if the given function is a trusted function whose only result may
+// be nil (see nilableResultFuncs).
+func HasNilableResult(funcObj *types.Func) bool {
+	for _, f := range nilableResultFuncs {
+		if f.matchFunc(funcObj) {
+			return true
+		}
+	}
+	return false
+}
+
+// AsStore checks a function call AST node to see if it is one of the trusted functions that store
+// a value in their receiver (see storeFuncs), and if it is then returns the receiver and the stored
+// value along with a bool indicating success or failure. For example, `p` and `v` are returned for
+// `p.Store(v)`, where `p` is of type `atomic.Pointer[T]`.
+func AsStore(call *ast.CallExpr, p *analysis.Pass) (recv ast.Expr, value ast.Expr, ok bool) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil, nil, false
+	}
+	for _, f := range storeFuncs {
+		if f.match(call, p) {
+			return sel.X, call.Args[0], true
+		}
+	}
+	return nil, nil, false
+}
+
+// errorsAsFuncs defines the trusted functions of the form `As(err, &target)` that assign a
+// non-nil value to `target` if they return true.
+var errorsAsFuncs = []trustedFuncSig{
+	{
+		kind:           _func,
+		enclosingRegex: regexp.MustCompile(`^(errors|github\.com/pkg/errors)$`),
+		funcNameRegex:  regexp.MustCompile(`^As$`),
+	},
+}
+
+// AsErrorsAs checks a function call AST node to see if it is one of the trusted functions of the
+// form `errors.As(err, &target)` (see errorsAsFuncs), and if it is then returns the `target`
+// expression along with a bool indicating success or failure. Calls whose second argument is not
+// of the form `&target` are not matched.
+func AsErrorsAs(call *ast.CallExpr, p *analysis.Pass) (ast.Expr, bool) {
+	if len(call.Args) != 2 {
+		return nil, false
+	}
+	addr, ok := call.Args[1].(*ast.UnaryExpr)
+	if !ok || addr.Op != token.AND {
+		return nil, false
+	}
+	for _, f := range errorsAsFuncs {
+		if f.match(call, p) {
+			return addr.X, true
+		}
+	}
+	return nil, false
+}
@@ -18,6 +18,7 @@ package global
 import (
 	"go/ast"
 	"go/token"
+	"go/types"
 	"reflect"
 
 	"go.uber.org/nilaway/annotation"
@@ -44,19 +45,29 @@ func run(pass *analysis.Pass) ([]annotation.FullTrigger, error) {
 		return nil, nil
 	}
 
-	var fullTriggers []annotation.FullTrigger
+	var files []*ast.File
 	for _, file := range pass.Files {
-		if !conf.IsFileInScope(file) {
-			continue
+		if conf.IsFileInScope(file) {
+			files = append(files, file)
 		}
+	}
+	initAssigned := make(map[*types.Var]bool)
+	for v := range initAssignedGlobals(pass, files) {
+		initAssigned[v] = true
+	}
 
+	// The variables assigned in init() functions are still zero-valued when the initializers of
+	// the package-level variables are evaluated (the functions called by them are checked by the
+	// function analyzer, see UnassignedGlobals).
+	fullTriggers := initializerDerefs(pass, initAssigned)
+	for _, file := range files {
 		for _, decl := range file.Decls {
 			genDecl, ok := decl.(*ast.GenDecl)
 			if !ok || genDecl.Tok != token.VAR {
 				continue
 			}
 			for _, spec := range genDecl.Specs {
-				fullTriggers = append(fullTriggers, analyzeValueSpec(pass, spec.(*ast.ValueSpec))...)
+				fullTriggers = append(fullTriggers, analyzeValueSpec(pass, spec.(*ast.ValueSpec), initAssigned)...)
 			}
 		}
 	}
@@ -23,8 +23,9 @@ import (
 	"golang.org/x/tools/go/analysis"
 )
 
-// analyzeValueSpec returns full triggers corresponding to the declaration
-func analyzeValueSpec(pass *analysis.Pass, spec *ast.ValueSpec) []annotation.FullTrigger {
+// analyzeValueSpec returns full triggers corresponding to the declaration. initAssigned is the set
+// of global variables declared without values that are definitely assigned in init() functions.
+func analyzeValueSpec(pass *analysis.Pass, spec *ast.ValueSpec, initAssigned map[*types.Var]bool) []annotation.FullTrigger {
 	var fullTriggers []annotation.FullTrigger
 
 	consumers := getGlobalConsumers(pass, spec)
@@ -38,16 +39,22 @@ func analyzeValueSpec(pass *analysis.Pass, spec *ast.ValueSpec) []annotation.Ful
 		// Case: variables are not initialized
 		// All the variables in this case have same type
 		if len(spec.Values) == 0 {
+			// The variables assigned in init() functions are not nil when any other code reads
+			// them, except for the code that may run before the assignments (see getProducerForVar,
+			// initializerDerefs, and UnassignedGlobals).
+			if initAssigned[pass.TypesInfo.ObjectOf(ident).(*types.Var)] {
+				continue
+			}
 			prod = &annotation.ProduceTrigger{
 				Annotation: &annotation.ProduceTriggerTautology{},
 				Expr:       ident,
 			}
 		} else if len(spec.Names) == len(spec.Values) {
 			// Case: variables are initialized and the assignment is 1-1
-			prod = getGlobalProducer(pass, spec, i, i)
+			prod = getGlobalProducer(pass, spec, i, i, initAssigned)
 		} else {
 			// Case: variables are initialized using a multiple return function
-			prod = getGlobalProducer(pass, spec, i, 0)
+			prod = getGlobalProducer(pass, spec, i, 0, initAssigned)
 		}
 
 		if prod != nil {
@@ -87,7 +94,7 @@ func getGlobalConsumers(pass *analysis.Pass, valspec *ast.ValueSpec) []*annotati
 
 // Returns a producer in the cases: 1) func call 2) literal nil 3) another global var 4) struct field/method.
 // In all other cases, it returns nil.
-func getGlobalProducer(pass *analysis.Pass, valspec *ast.ValueSpec, lid int, rid int) *annotation.ProduceTrigger {
+func getGlobalProducer(pass *analysis.Pass, valspec *ast.ValueSpec, lid int, rid int, initAssigned map[*types.Var]bool) *annotation.ProduceTrigger {
 	switch rhs := valspec.Values[rid].(type) {
 	case *ast.CallExpr:
 		if ident, ok := rhs.Fun.(*ast.Ident); ok {
@@ -111,7 +118,7 @@ func getGlobalProducer(pass *analysis.Pass, valspec *ast.ValueSpec, lid int, rid
 			}
 		}
 		// if rhs is another global
-		return getProducerForVar(pass, rhs)
+		return getProducerForVar(pass, rhs, initAssigned)
 	case *ast.SelectorExpr:
 		// Struct field access
 		return getProducerForField(pass, rhs.Sel)
@@ -120,13 +127,25 @@ func getGlobalProducer(pass *analysis.Pass, valspec *ast.ValueSpec, lid int, rid
 	return nil
 }
 
-func getProducerForVar(pass *analysis.Pass, rhs *ast.Ident) *annotation.ProduceTrigger {
+func getProducerForVar(pass *analysis.Pass, rhs *ast.Ident, initAssigned map[*types.Var]bool) *annotation.ProduceTrigger {
 	rhsVar, ok := pass.TypesInfo.ObjectOf(rhs).(*types.Var)
 	if !ok || !annotation.VarIsGlobal(rhsVar) {
 		// If rhs is not a global variable (e.g., a constant), we ignore it.
 		return nil
 	}
 
+	// The package-level variables are initialized before any init() function runs, hence the
+	// variables assigned in init() functions are still zero-valued here.
+	if initAssigned[rhsVar] {
+		return &annotation.ProduceTrigger{
+			Annotation: &annotation.GlobalVarReadBeforeInit{
+				ProduceTriggerTautology: &annotation.ProduceTriggerTautology{},
+				VarObj:                  rhsVar,
+			},
+			Expr: rhs,
+		}
+	}
+
 	return &annotation.ProduceTrigger{
 		Annotation: &annotation.GlobalVarRead{
 			TriggerIfNilable: &annotation.TriggerIfNilable{
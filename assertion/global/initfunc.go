@@ -0,0 +1,282 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package global
+
+import (
+	"go/ast"
+	"go/types"
+
+	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/util"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// initAssignment is the definite assignment of a global variable in an init() function.
+type initAssignment struct {
+	// init is the index of the init() function among the init() functions of the package, in the
+	// order they run (i.e., the order of the files and then the order of the declarations).
+	init int
+	// stmt is the index of the assignment in the top-level statements of the init() function.
+	stmt int
+}
+
+// initAssignedGlobals returns the global variables of the package that are declared without values
+// (i.e., zero-initialized), but are definitely assigned by an init() function before any other code
+// (except for the initialization of package-level variables and the code that runs before the
+// assignment, see UnassignedGlobals) runs, along with their first definite assignments. A variable
+// is definitely assigned if the assignment is a top-level statement of the init() body that is not
+// preceded by any (possibly nested) return statement. Note that the assigned values themselves are
+// checked against the annotations of the variables by the analysis of the init() functions.
+func initAssignedGlobals(pass *analysis.Pass, files []*ast.File) map[*types.Var]initAssignment {
+	// The variables with initializers are already covered by the regular analysis of their values.
+	initialized := make(map[*types.Var]bool)
+	for _, initializer := range pass.TypesInfo.InitOrder {
+		for _, v := range initializer.Lhs {
+			initialized[v] = true
+		}
+	}
+
+	assigned := make(map[*types.Var]initAssignment)
+	for i, funcDecl := range initFuncs(files) {
+		for j, stmt := range funcDecl.Body.List {
+			if containsReturn(stmt) {
+				break
+			}
+			assignStmt, ok := stmt.(*ast.AssignStmt)
+			if !ok {
+				continue
+			}
+			for _, lhs := range assignStmt.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				v, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+				if !ok || !annotation.VarIsGlobal(v) || v.Pkg() != pass.Pkg || initialized[v] {
+					continue
+				}
+				if _, ok := assigned[v]; !ok {
+					assigned[v] = initAssignment{init: i, stmt: j}
+				}
+			}
+		}
+	}
+	return assigned
+}
+
+// initFuncs returns the init() functions in the files, in the order they run.
+func initFuncs(files []*ast.File) []*ast.FuncDecl {
+	var funcs []*ast.FuncDecl
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if ok && funcDecl.Recv == nil && funcDecl.Name.Name == "init" && funcDecl.Body != nil {
+				funcs = append(funcs, funcDecl)
+			}
+		}
+	}
+	return funcs
+}
+
+// UnassignedGlobals returns, for each function (i.e., *ast.FuncDecl or *ast.FuncLit) in the files
+// that may run before the definite assignments of some global variables in init() functions (see
+// initAssignedGlobals), the set of such variables that may still be zero-valued at the entry of the
+// function. These are the init() functions up to the one containing the assignment, and the
+// functions (transitively) referenced by the initializers of the package-level variables, by the
+// earlier init() functions, or by the statements up to the assignment. Note that we conservatively
+// consider a function referenced before the assignment as running before it everywhere, and that we
+// do not follow the dynamic dispatches (e.g., calls via interfaces) that cannot be resolved
+// syntactically.
+func UnassignedGlobals(pass *analysis.Pass, files []*ast.File) map[ast.Node]map[*types.Var]bool {
+	assigned := initAssignedGlobals(pass, files)
+	if len(assigned) == 0 {
+		return nil
+	}
+
+	r := newReachability(pass, files)
+	var initializers []ast.Node
+	for _, initializer := range pass.TypesInfo.InitOrder {
+		initializers = append(initializers, initializer.Rhs)
+	}
+	beforeInits := r.reachableFrom(initializers...)
+
+	// The functions reachable from each top-level statement of the init() functions.
+	inits := initFuncs(files)
+	stmtReached := make([][]map[ast.Node]bool, len(inits))
+	for i, funcDecl := range inits {
+		for _, stmt := range funcDecl.Body.List {
+			stmtReached[i] = append(stmtReached[i], r.reachableFrom(stmt))
+		}
+	}
+
+	res := make(map[ast.Node]map[*types.Var]bool)
+	add := func(fn ast.Node, v *types.Var) {
+		if res[fn] == nil {
+			res[fn] = make(map[*types.Var]bool)
+		}
+		res[fn][v] = true
+	}
+	for v, assignment := range assigned {
+		for fn := range beforeInits {
+			add(fn, v)
+		}
+		for i, funcDecl := range inits[:assignment.init+1] {
+			// The init() functions themselves are never referenced by any code, so we add them here.
+			add(funcDecl, v)
+			reached := stmtReached[i]
+			if i == assignment.init {
+				// The assigned value is evaluated before the assignment as well.
+				reached = reached[:assignment.stmt+1]
+			}
+			for _, fns := range reached {
+				for fn := range fns {
+					add(fn, v)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// reachability computes the functions in the package that are (transitively) referenced by the
+// given nodes, along with the function literals in them.
+type reachability struct {
+	pass *analysis.Pass
+	// decls maps the functions and methods declared in the package to their declarations.
+	decls map[*types.Func]*ast.FuncDecl
+}
+
+func newReachability(pass *analysis.Pass, files []*ast.File) *reachability {
+	decls := make(map[*types.Func]*ast.FuncDecl)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Body == nil {
+				continue
+			}
+			if funcObj, ok := pass.TypesInfo.Defs[funcDecl.Name].(*types.Func); ok {
+				decls[funcObj] = funcDecl
+			}
+		}
+	}
+	return &reachability{pass: pass, decls: decls}
+}
+
+// reachableFrom returns the functions reachable from the nodes, including the function literals
+// in the nodes themselves.
+func (r *reachability) reachableFrom(nodes ...ast.Node) map[ast.Node]bool {
+	reached := make(map[ast.Node]bool)
+	var worklist []*ast.FuncDecl
+	visit := func(node ast.Node) {
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				reached[n] = true
+			case *ast.Ident:
+				funcObj, ok := r.pass.TypesInfo.Uses[n].(*types.Func)
+				if !ok {
+					return true
+				}
+				if funcDecl, ok := r.decls[funcObj.Origin()]; ok && !reached[funcDecl] {
+					reached[funcDecl] = true
+					worklist = append(worklist, funcDecl)
+				}
+			}
+			return true
+		})
+	}
+	for _, node := range nodes {
+		visit(node)
+	}
+	for len(worklist) > 0 {
+		funcDecl := worklist[len(worklist)-1]
+		worklist = worklist[:len(worklist)-1]
+		visit(funcDecl.Body)
+	}
+	return reached
+}
+
+// initializerDerefs returns the full triggers for the dereferences of the variables assigned in
+// init() functions in the initializers of the package-level variables, e.g., `var x = g.f` or
+// `var x = *g`, which always happen before the variables are assigned.
+func initializerDerefs(pass *analysis.Pass, initAssigned map[*types.Var]bool) []annotation.FullTrigger {
+	if len(initAssigned) == 0 {
+		return nil
+	}
+
+	var fullTriggers []annotation.FullTrigger
+	deref := func(expr ast.Expr, consumer annotation.ConsumingAnnotationTrigger) {
+		ident, ok := astutil.Unparen(expr).(*ast.Ident)
+		if !ok {
+			return
+		}
+		v, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+		if !ok || !initAssigned[v] {
+			return
+		}
+		fullTriggers = append(fullTriggers, annotation.FullTrigger{
+			Producer: &annotation.ProduceTrigger{
+				Annotation: &annotation.GlobalVarReadBeforeInit{
+					ProduceTriggerTautology: &annotation.ProduceTriggerTautology{},
+					VarObj:                  v,
+				},
+				Expr: ident,
+			},
+			Consumer: &annotation.ConsumeTrigger{
+				Annotation: consumer,
+				Expr:       ident,
+				Guards:     util.NoGuards(),
+			},
+		})
+	}
+	for _, initializer := range pass.TypesInfo.InitOrder {
+		ast.Inspect(initializer.Rhs, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncLit:
+				// The function literals are checked by the function analyzer.
+				return false
+			case *ast.StarExpr:
+				deref(node.X, &annotation.PtrLoad{ConsumeTriggerTautology: &annotation.ConsumeTriggerTautology{}})
+			case *ast.SelectorExpr:
+				// Only the accesses of the fields via pointers dereference the variables.
+				if sel, ok := pass.TypesInfo.Selections[node]; ok && sel.Kind() == types.FieldVal && sel.Indirect() {
+					deref(node.X, &annotation.FldAccess{
+						ConsumeTriggerTautology: &annotation.ConsumeTriggerTautology{},
+						Sel:                     sel.Obj(),
+					})
+				}
+			}
+			return true
+		})
+	}
+	return fullTriggers
+}
+
+// containsReturn returns true if the statement contains a return statement of the enclosing
+// function, i.e., not counting the ones in the nested function literals.
+func containsReturn(stmt ast.Stmt) bool {
+	found := false
+	ast.Inspect(stmt, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.ReturnStmt:
+			found = true
+		case *ast.FuncLit:
+			return false
+		}
+		return !found
+	})
+	return found
+}
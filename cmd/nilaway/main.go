@@ -23,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"go.uber.org/nilaway"
 	"go.uber.org/nilaway/config"
@@ -49,6 +50,9 @@ var (
 	_excludeErrorsInFiles string
 )
 
+// _warningsMu serializes the printing of the warnings from the packages analyzed in parallel.
+var _warningsMu sync.Mutex
+
 func run(pass *analysis.Pass) (interface{}, error) {
 	// NilAway by default analyzes all packages, including dependencies. Even if specified to
 	// exclude packages from analysis via configurations, NilAway can still report errors on
@@ -67,26 +71,46 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		return nil, fmt.Errorf("parse file prefixes for error exclusion: %w", err)
 	}
 
-	// Override the report function to add error filtering logic.
-	report := pass.Report
-	pass.Report = func(d analysis.Diagnostic) {
+	included := func(d analysis.Diagnostic) bool {
 		p := pass.Fset.File(d.Pos).Name()
 		for _, e := range excludes {
 			if strings.HasPrefix(p, e) {
-				return
+				return false
 			}
 		}
 
 		for _, i := range includes {
 			if strings.HasPrefix(p, i) {
-				report(d)
-				return
+				return true
 			}
 		}
+		return false
+	}
+
+	// Override the report function to add error filtering logic.
+	report := pass.Report
+	pass.Report = func(d analysis.Diagnostic) {
+		if included(d) {
+			report(d)
+		}
 	}
 
 	// Delegate the real analysis run to the original nilaway analyzer.
-	return nilaway.Analyzer.Run(pass)
+	res, err := nilaway.Analyzer.Run(pass)
+	if err != nil {
+		return nil, err
+	}
+
+	// The warnings (i.e., the diagnostics of the downgraded categories) are printed to stderr
+	// instead of being reported, such that they do not fail the run.
+	_warningsMu.Lock()
+	defer _warningsMu.Unlock()
+	for _, d := range res.(*nilaway.Result).Warnings {
+		if included(d) {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", pass.Fset.Position(d.Pos), d.Message)
+		}
+	}
+	return res, nil
 }
 
 // parseFilePrefixes parses the comma-separated list of file prefixes, converts them to absolute
@@ -133,5 +157,24 @@ func main() {
 	flag.StringVar(&_includeErrorsInFiles, "include-errors-in-files", wd, "A comma-separated list of file prefixes to report errors, default is current working directory.")
 	flag.StringVar(&_excludeErrorsInFiles, "exclude-errors-in-files", "", "A comma-separated list of file prefixes to exclude from error reporting. This takes precedence over include-errors-in-files.")
 
+	// `nilaway annotate [flags] <packages>` runs inference and writes the inferred annotations of
+	// the exported API back into the source, by turning on the annotate mode and applying the
+	// suggested fixes.
+	if len(os.Args) > 1 && os.Args[1] == "annotate" {
+		os.Args = append([]string{os.Args[0], "-" + config.AnnotateFlag, "-" + config.PrettyPrintFlag + "=false", "-fix"}, os.Args[2:]...)
+	}
+
+	// `nilaway wholeprogram [flags] <packages>` runs NilAway with the whole-program driver, which
+	// can optionally perform a global inference pass over all packages (see package driver).
+	if len(os.Args) > 1 && os.Args[1] == "wholeprogram" {
+		os.Exit(runWholeProgram(os.Args[2:]))
+	}
+
+	// `nilaway repro [flags] -pos <file>:<line>[:<col>] <package>` minimizes the package to a
+	// reproducer of the diagnostic at the position (see package repro).
+	if len(os.Args) > 1 && os.Args[1] == "repro" {
+		os.Exit(runRepro(os.Args[2:]))
+	}
+
 	singlechecker.Main(Analyzer)
 }
@@ -0,0 +1,101 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/nilaway/repro"
+)
+
+// runRepro implements `nilaway repro [flags] -pos <file>:<line>[:<col>] <package>`, which
+// minimizes the package while the diagnostic at the position still reproduces (see package
+// repro), and writes the reproducer to the output directory (or prints it to stdout if not
+// given). It returns the exit code: 1 for failures, and 0 otherwise.
+func runRepro(args []string) int {
+	pos := flag.String("pos", "", "The position <file>:<line>[:<col>] of the diagnostic to reproduce")
+	out := flag.String("out", "", "The directory to write the reproducer to, e.g., testdata/src/go.uber.org/foo (default: print to stdout)")
+	if err := flag.CommandLine.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse flags: %v\n", err)
+		return 1
+	}
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "expect exactly one package to minimize")
+		return 1
+	}
+	position, err := parsePosition(*pos)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse position %q: %v\n", *pos, err)
+		return 1
+	}
+
+	res, err := repro.Minimize(context.Background(), flag.Arg(0), position, repro.Options{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to minimize reproducer: %v\n", err)
+		return 1
+	}
+
+	if *out != "" {
+		if err := res.Write(*out); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write reproducer: %v\n", err)
+			return 1
+		}
+	} else {
+		names := make([]string, 0, len(res.Files))
+		for name := range res.Files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("// ---- %s ----\n%s\n", name, res.Files[name])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "minimized reproducer after %d NilAway runs\n", res.Runs)
+	for _, imp := range res.Imports {
+		fmt.Fprintf(os.Stderr, "warning: the reproducer still imports %q, which must be made available in the test data\n", imp)
+	}
+	return 0
+}
+
+// parsePosition parses the position in the form of <file>:<line>[:<col>], where the file is
+// converted to an absolute path.
+func parsePosition(s string) (token.Position, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return token.Position{}, fmt.Errorf("expect <file>:<line>[:<col>]")
+	}
+	file, err := filepath.Abs(parts[0])
+	if err != nil {
+		return token.Position{}, fmt.Errorf("convert %q to absolute path: %w", parts[0], err)
+	}
+	position := token.Position{Filename: file}
+	if position.Line, err = strconv.Atoi(parts[1]); err != nil || position.Line < 1 {
+		return token.Position{}, fmt.Errorf("invalid line %q", parts[1])
+	}
+	if len(parts) == 3 {
+		if position.Column, err = strconv.Atoi(parts[2]); err != nil || position.Column < 1 {
+			return token.Position{}, fmt.Errorf("invalid column %q", parts[2])
+		}
+	}
+	return position, nil
+}
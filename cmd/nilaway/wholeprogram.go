@@ -0,0 +1,100 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/nilaway/driver"
+	"go.uber.org/nilaway/util"
+)
+
+// runWholeProgram implements `nilaway wholeprogram [flags] <packages>`, which analyzes the
+// packages with the whole-program driver (see package driver) instead of singlechecker, and
+// returns the exit code: 1 for failures, 3 if any diagnostics are reported, and 0 otherwise (same
+// as singlechecker).
+func runWholeProgram(args []string) int {
+	global := flag.Bool("global", false, "Whether to run the global inference pass over all packages after the modular analysis")
+	tests := flag.Bool("test", false, "Whether to analyze the test packages as well")
+	if err := flag.CommandLine.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse flags: %v\n", err)
+		return 1
+	}
+
+	includes, err := parseFilePrefixes(_includeErrorsInFiles)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse file prefixes for error inclusion: %v\n", err)
+		return 1
+	}
+	excludes, err := parseFilePrefixes(_excludeErrorsInFiles)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse file prefixes for error exclusion: %v\n", err)
+		return 1
+	}
+
+	res, err := driver.Run(context.Background(), flag.Args(), driver.Options{Tests: *tests, Global: *global})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to run NilAway: %v\n", err)
+		return 1
+	}
+
+	reported := false
+	report := func(d driver.Diagnostic, prefix string) {
+		position := res.Fset.Position(d.Pos)
+		if !hasAnyPrefix(position.Filename, includes) || hasAnyPrefix(position.Filename, excludes) {
+			return
+		}
+		msg := d.Message
+		// The diagnostics of the downgraded categories are printed as warnings, which do not fail
+		// the run.
+		warning := res.Config.IsCategoryDowngraded(d.Category, d.Package.Types)
+		if res.Config.PrettyPrint {
+			if warning {
+				msg = util.PrettyPrintWarningMessage(msg)
+			} else {
+				msg = util.PrettyPrintErrorMessage(msg)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s%s\n", position, prefix, msg)
+		if !warning {
+			reported = true
+		}
+	}
+	for _, d := range res.Diagnostics {
+		report(d, "")
+	}
+	for _, d := range res.GlobalDiagnostics {
+		report(d, "[global inference] ")
+	}
+
+	if reported {
+		return 3
+	}
+	return 0
+}
+
+// hasAnyPrefix returns true iff the string has any of the prefixes.
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
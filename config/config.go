@@ -17,9 +17,11 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"go/ast"
 	"go/types"
 	"reflect"
+	"slices"
 	"strings"
 
 	"go.uber.org/nilaway/util/asthelper"
@@ -36,6 +38,28 @@ type Config struct {
 	ExperimentalStructInitEnable bool
 	// ExperimentalAnonymousFuncEnable indicates whether experimental anonymous function support is enabled.
 	ExperimentalAnonymousFuncEnable bool
+	// Strict indicates whether strict mode is enabled for all packages, i.e., whether the
+	// optimistic (unsound) assumptions NilAway makes to reduce false positives are disabled.
+	Strict bool
+	// Annotate indicates whether NilAway should suggest writing the inferred nilabilities of the
+	// exported API back into the source as annotations, instead of reporting potential nil panics.
+	Annotate bool
+	// AnnotateNonnil indicates whether `nonnil` annotations should also be suggested for the sites
+	// that are nilable by default (e.g., slices) but inferred nonnil, when Annotate is set.
+	AnnotateNonnil bool
+	// CollectAnnotations indicates whether the inferred annotations of the exported API should be
+	// collected even if Annotate is not set, e.g., for the structured report of nilaway.Run.
+	CollectAnnotations bool
+	// StubFiles is the list of stub files that annotate the functions whose sources are not
+	// available for annotating, e.g., `always-nonnil(result 0)` for third-party constructors.
+	StubFiles []string
+	// FunctionWorkers is the maximum number of functions analyzed concurrently in a package, where
+	// zero means runtime.GOMAXPROCS(0).
+	FunctionWorkers int
+	// MemoryBudget is the soft limit (in bytes) on the heap size when analyzing functions, where
+	// zero means no limit. While it is exceeded, the analyses of the longest-running functions are
+	// cancelled and counted in the statistics.
+	MemoryBudget uint64
 
 	// includePkgs is the list of packages to analyze.
 	includePkgs []string
@@ -46,6 +70,82 @@ type Config struct {
 	// string, will cause the file to be excluded from analysis. Examples include "@generated" and
 	// "Code generated by".
 	excludeFileDocStrings []string
+	// strictPkgs is the list of packages to analyze in strict mode, even if Strict is not set.
+	strictPkgs []string
+	// disabledCategories is the list of diagnostic categories that are not reported.
+	disabledCategories []categoryRule
+	// downgradedCategories is the list of diagnostic categories that are reported as warnings
+	// instead of errors.
+	downgradedCategories []categoryRule
+}
+
+// Categories are the stable codes of the classes of diagnostics (see diagnostic.Category), which
+// are the valid categories in the category configurations (e.g., DisableCategoriesFlag).
+var Categories = []string{
+	"ptr-load",
+	"field-access",
+	"map-access",
+	"map-write",
+	"slice-access",
+	"func-value-call",
+	"typed-nil-interface",
+	"nil-receiver",
+	"error-result",
+	"field-assign",
+	"global-assign",
+	"arg-pass",
+	"nilable-return",
+	"interface-covariance",
+	"deep-assign",
+	"deep-read",
+	"other",
+}
+
+// categoryRule applies to the diagnostics of a category in the packages with a given prefix.
+type categoryRule struct {
+	category  string
+	pkgPrefix string
+}
+
+// parseCategoryRules parses a comma-separated list of "<category>" (for all packages) or
+// "<category>:<package prefix>" entries. Unknown categories (e.g., typos) are rejected, since they
+// would otherwise silently have no effect.
+func parseCategoryRules(s string) ([]categoryRule, error) {
+	var rules []categoryRule
+	for _, entry := range strings.Split(s, ",") {
+		category, pkgPrefix, _ := strings.Cut(entry, ":")
+		if !slices.Contains(Categories, category) {
+			return nil, fmt.Errorf("unknown diagnostic category %q (valid categories: %s)", category, strings.Join(Categories, ", "))
+		}
+		rules = append(rules, categoryRule{category: category, pkgPrefix: pkgPrefix})
+	}
+	return rules, nil
+}
+
+// matchCategoryRules returns true iff any of the rules applies to the category in the package.
+func matchCategoryRules(rules []categoryRule, category string, pkg *types.Package) bool {
+	for _, rule := range rules {
+		if rule.category != category {
+			continue
+		}
+		if rule.pkgPrefix == "" || (pkg != nil && strings.HasPrefix(pkg.Path(), rule.pkgPrefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCategoryDisabled returns true iff the diagnostics of the passed category should not be
+// reported for the passed package.
+func (c *Config) IsCategoryDisabled(category string, pkg *types.Package) bool {
+	return matchCategoryRules(c.disabledCategories, category, pkg)
+}
+
+// IsCategoryDowngraded returns true iff the diagnostics of the passed category should be reported
+// as warnings instead of errors for the passed package. Note that the warnings are not reported to
+// the linter drivers at all, since they treat every reported diagnostic as an error.
+func (c *Config) IsCategoryDowngraded(category string, pkg *types.Package) bool {
+	return matchCategoryRules(c.downgradedCategories, category, pkg)
 }
 
 // IsPkgInScope returns true iff the passed package is in scope for analysis, i.e., it is in the
@@ -71,6 +171,24 @@ func (c *Config) IsPkgInScope(pkg *types.Package) bool {
 	return false
 }
 
+// IsPkgStrict returns true iff the passed package should be analyzed in strict mode, i.e., strict
+// mode is enabled globally or the package is in the configured strict list.
+func (c *Config) IsPkgStrict(pkg *types.Package) bool {
+	if c.Strict {
+		return true
+	}
+	if pkg == nil {
+		return false
+	}
+
+	for _, strict := range c.strictPkgs {
+		if strings.HasPrefix(pkg.Path(), strict) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsFileInScope returns true iff we should analyze the file. It checks the docstring of the file
 // and returns false if any of the strings in ExcludeFileDocStrings appear in the file docstring.
 func (c *Config) IsFileInScope(file *ast.File) bool {
@@ -135,6 +253,29 @@ const (
 	ExperimentalStructInitEnableFlag = "experimental-struct-init"
 	// ExperimentalAnonymousFunctionFlag is the flag name for the experimental anonymous function support.
 	ExperimentalAnonymousFunctionFlag = "experimental-anonymous-function"
+	// StrictFlag is the flag name for enabling strict mode for all packages.
+	StrictFlag = "strict"
+	// StrictPkgsFlag is the flag name for the package prefixes to analyze in strict mode.
+	StrictPkgsFlag = "strict-pkgs"
+	// StubFilesFlag is the flag name for the stub files that annotate functions.
+	StubFilesFlag = "stub-files"
+	// AnnotateFlag is the flag name for suggesting inferred annotations instead of reporting errors.
+	AnnotateFlag = "annotate"
+	// AnnotateNonnilFlag is the flag name for also suggesting inferred `nonnil` annotations.
+	AnnotateNonnilFlag = "annotate-nonnil"
+	// CollectAnnotationsFlag is the flag name for collecting the inferred annotations without
+	// suggesting them.
+	CollectAnnotationsFlag = "collect-annotations"
+	// DisableCategoriesFlag is the flag name for the diagnostic categories that are not reported.
+	DisableCategoriesFlag = "disable-categories"
+	// DowngradeCategoriesFlag is the flag name for the diagnostic categories that are reported as
+	// warnings.
+	DowngradeCategoriesFlag = "downgrade-categories"
+	// FunctionWorkersFlag is the flag name for the maximum number of functions analyzed concurrently.
+	FunctionWorkersFlag = "function-workers"
+	// MemoryBudgetFlag is the flag name for the soft limit (in MiB) on the heap size when analyzing
+	// functions.
+	MemoryBudgetFlag = "memory-budget-mb"
 )
 
 // newFlagSet returns a flag set to be used in the nilaway config analyzer.
@@ -150,6 +291,16 @@ func newFlagSet() flag.FlagSet {
 	_ = fs.String(ExcludeFileDocStringsFlag, "", "Comma-separated list of docstrings to exclude from analysis")
 	_ = fs.Bool(ExperimentalStructInitEnableFlag, false, "Whether to enable experimental struct initialization support")
 	_ = fs.Bool(ExperimentalAnonymousFunctionFlag, false, "Whether to enable experimental anonymous function support")
+	_ = fs.Bool(StrictFlag, false, "Whether to disable optimistic assumptions (e.g., on length checks) for all packages")
+	_ = fs.String(StrictPkgsFlag, "", "Comma-separated list of packages to analyze in strict mode")
+	_ = fs.String(StubFilesFlag, "", "Comma-separated list of stub files that annotate functions")
+	_ = fs.Bool(AnnotateFlag, false, "Whether to suggest the inferred annotations of the exported API (as fixes) instead of reporting errors")
+	_ = fs.Bool(AnnotateNonnilFlag, false, "Whether to also suggest nonnil annotations for the default nilable sites (e.g., slices) that are inferred nonnil")
+	_ = fs.Bool(CollectAnnotationsFlag, false, "Whether to collect the inferred annotations of the exported API (e.g., for programmatic runs) without suggesting them")
+	_ = fs.String(DisableCategoriesFlag, "", "Comma-separated list of diagnostic categories (optionally as <category>:<package prefix>) to not report")
+	_ = fs.String(DowngradeCategoriesFlag, "", "Comma-separated list of diagnostic categories (optionally as <category>:<package prefix>) to report as warnings")
+	_ = fs.Int(FunctionWorkersFlag, 0, "Maximum number of functions analyzed concurrently in a package (0 means GOMAXPROCS)")
+	_ = fs.Uint64(MemoryBudgetFlag, 0, "Soft limit (in MiB) on the heap size when analyzing functions, beyond which the analyses of the longest-running functions are cancelled (0 means no limit)")
 
 	return *fs
 }
@@ -177,6 +328,18 @@ func run(pass *analysis.Pass) (any, error) {
 	if enableAnonymousFunc, ok := pass.Analyzer.Flags.Lookup(ExperimentalAnonymousFunctionFlag).Value.(flag.Getter).Get().(bool); ok {
 		conf.ExperimentalAnonymousFuncEnable = enableAnonymousFunc
 	}
+	if strict, ok := pass.Analyzer.Flags.Lookup(StrictFlag).Value.(flag.Getter).Get().(bool); ok {
+		conf.Strict = strict
+	}
+	if annotate, ok := pass.Analyzer.Flags.Lookup(AnnotateFlag).Value.(flag.Getter).Get().(bool); ok {
+		conf.Annotate = annotate
+	}
+	if annotateNonnil, ok := pass.Analyzer.Flags.Lookup(AnnotateNonnilFlag).Value.(flag.Getter).Get().(bool); ok {
+		conf.AnnotateNonnil = annotateNonnil
+	}
+	if collectAnnotations, ok := pass.Analyzer.Flags.Lookup(CollectAnnotationsFlag).Value.(flag.Getter).Get().(bool); ok {
+		conf.CollectAnnotations = collectAnnotations
+	}
 	if include, ok := pass.Analyzer.Flags.Lookup(IncludePkgsFlag).Value.(flag.Getter).Get().(string); ok && include != "" {
 		conf.includePkgs = strings.Split(include, ",")
 	}
@@ -186,6 +349,33 @@ func run(pass *analysis.Pass) (any, error) {
 	if docstrings, ok := pass.Analyzer.Flags.Lookup(ExcludeFileDocStringsFlag).Value.(flag.Getter).Get().(string); ok && docstrings != "" {
 		conf.excludeFileDocStrings = strings.Split(docstrings, ",")
 	}
+	if strictPkgs, ok := pass.Analyzer.Flags.Lookup(StrictPkgsFlag).Value.(flag.Getter).Get().(string); ok && strictPkgs != "" {
+		conf.strictPkgs = strings.Split(strictPkgs, ",")
+	}
+	if stubFiles, ok := pass.Analyzer.Flags.Lookup(StubFilesFlag).Value.(flag.Getter).Get().(string); ok && stubFiles != "" {
+		conf.StubFiles = strings.Split(stubFiles, ",")
+	}
+	if disabled, ok := pass.Analyzer.Flags.Lookup(DisableCategoriesFlag).Value.(flag.Getter).Get().(string); ok && disabled != "" {
+		rules, err := parseCategoryRules(disabled)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -%s: %w", DisableCategoriesFlag, err)
+		}
+		conf.disabledCategories = rules
+	}
+	if downgraded, ok := pass.Analyzer.Flags.Lookup(DowngradeCategoriesFlag).Value.(flag.Getter).Get().(string); ok && downgraded != "" {
+		rules, err := parseCategoryRules(downgraded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -%s: %w", DowngradeCategoriesFlag, err)
+		}
+		conf.downgradedCategories = rules
+	}
+
+	if workers, ok := pass.Analyzer.Flags.Lookup(FunctionWorkersFlag).Value.(flag.Getter).Get().(int); ok {
+		conf.FunctionWorkers = workers
+	}
+	if budget, ok := pass.Analyzer.Flags.Lookup(MemoryBudgetFlag).Value.(flag.Getter).Get().(uint64); ok {
+		conf.MemoryBudget = budget << 20
+	}
 
 	return conf, nil
 }
@@ -0,0 +1,59 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestParseCategoryRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		s       string
+		want    []categoryRule
+		wantErr string
+	}{
+		{
+			name: "valid",
+			s:    "ptr-load,deep-read:example.com/pkg",
+			want: []categoryRule{{category: "ptr-load"}, {category: "deep-read", pkgPrefix: "example.com/pkg"}},
+		},
+		{name: "typo", s: "deepread", wantErr: `unknown diagnostic category "deepread"`},
+		{name: "underscore", s: "ptr-load,ptr_load:example.com/pkg", wantErr: `unknown diagnostic category "ptr_load"`},
+		{name: "empty", s: "ptr-load,", wantErr: `unknown diagnostic category ""`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rules, err := parseCategoryRules(tt.s)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, rules)
+		})
+	}
+}
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
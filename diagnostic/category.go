@@ -0,0 +1,155 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnostic
+
+import "go.uber.org/nilaway/annotation"
+
+// Category is the stable code of a class of diagnostics. It is included in the reported messages
+// (and set as the category of the reported diagnostics), and can be used in the configurations to
+// disable or downgrade a class of diagnostics. The codes must never change once released.
+type Category string
+
+const (
+	// CategoryPtrLoad is for dereferences of nilable pointers.
+	CategoryPtrLoad Category = "ptr-load"
+	// CategoryFieldAccess is for field accesses on nilable values.
+	CategoryFieldAccess Category = "field-access"
+	// CategoryMapAccess is for reads from nilable maps.
+	CategoryMapAccess Category = "map-access"
+	// CategoryMapWrite is for writes to nilable maps.
+	CategoryMapWrite Category = "map-write"
+	// CategorySliceAccess is for indexing into nilable slices.
+	CategorySliceAccess Category = "slice-access"
+	// CategoryFuncValueCall is for calls to nilable function values.
+	CategoryFuncValueCall Category = "func-value-call"
+	// CategoryTypedNilInterface is for nilable values that are wrapped in non-nil interfaces.
+	CategoryTypedNilInterface Category = "typed-nil-interface"
+	// CategoryNilReceiver is for method calls on nilable receivers that do not allow nil.
+	CategoryNilReceiver Category = "nil-receiver"
+	// CategoryErrorResult is for violations of the error return contracts.
+	CategoryErrorResult Category = "error-result"
+	// CategoryFieldAssign is for nilable values assigned to (or escaping via) nonnil fields.
+	CategoryFieldAssign Category = "field-assign"
+	// CategoryGlobalAssign is for nilable values assigned to nonnil global variables.
+	CategoryGlobalAssign Category = "global-assign"
+	// CategoryArgPass is for nilable values passed to nonnil parameters.
+	CategoryArgPass Category = "arg-pass"
+	// CategoryNilableReturn is for nilable values returned as nonnil results.
+	CategoryNilableReturn Category = "nilable-return"
+	// CategoryInterfaceCovariance is for violations of the covariance (contravariance) rules
+	// between the results (parameters) of interface methods and their implementations.
+	CategoryInterfaceCovariance Category = "interface-covariance"
+	// CategoryDeepAssign is for nilable values written into deeply nonnil containers.
+	CategoryDeepAssign Category = "deep-assign"
+	// CategoryDeepRead is for any dereference of a nilable value read from a container, e.g.,
+	// an element of a map or slice, regardless of the kind of the dereference.
+	CategoryDeepRead Category = "deep-read"
+	// CategoryOther is for the remaining diagnostics.
+	CategoryOther Category = "other"
+)
+
+// categoryOf returns the category of a diagnostic given the prestrings of the producer at the
+// nil source and the consumer at the dereference point of its nil flow. Either of them can be
+// nil if the corresponding end of the nil flow comes from an annotation.
+func categoryOf(producer, consumer annotation.Prestring) Category {
+	if isDeepRead(producer) {
+		return CategoryDeepRead
+	}
+
+	if l, ok := consumer.(annotation.LocatedPrestring); ok {
+		consumer = l.Contained
+	}
+	switch consumer.(type) {
+	case annotation.PtrLoadPrestring:
+		return CategoryPtrLoad
+	case annotation.FldAccessPrestring:
+		return CategoryFieldAccess
+	case annotation.MapAccessPrestring:
+		return CategoryMapAccess
+	case annotation.MapWrittenToPrestring:
+		return CategoryMapWrite
+	case annotation.SliceAccessPrestring:
+		return CategorySliceAccess
+	case annotation.FuncValueCallPrestring:
+		return CategoryFuncValueCall
+	case annotation.TypedNilInInterfacePrestring:
+		return CategoryTypedNilInterface
+	case annotation.RecvPassPrestring:
+		return CategoryNilReceiver
+	case annotation.UseAsErrorResultPrestring,
+		annotation.UseAsNonErrorRetDependentOnErrorRetNilabilityPrestring,
+		annotation.UseAsErrorRetWithNilabilityUnknownPrestring:
+		return CategoryErrorResult
+	case annotation.FldAssignPrestring,
+		annotation.ArgFldPassPrestring,
+		annotation.FldEscapePrestring,
+		annotation.UseAsFldOfReturnPrestring:
+		return CategoryFieldAssign
+	case annotation.GlobalVarAssignPrestring:
+		return CategoryGlobalAssign
+	case annotation.ArgPassPrestring,
+		annotation.ArgPassDeepPrestring,
+		annotation.CallbackArgPassPrestring:
+		return CategoryArgPass
+	case annotation.UseAsReturnPrestring,
+		annotation.UseAsReturnDeepPrestring,
+		annotation.CallbackReturnPrestring:
+		return CategoryNilableReturn
+	case annotation.InterfaceResultFromImplementationPrestring,
+		annotation.MethodParamFromInterfacePrestring:
+		return CategoryInterfaceCovariance
+	case annotation.SliceAssignPrestring,
+		annotation.ArrayAssignPrestring,
+		annotation.PtrAssignPrestring,
+		annotation.MapAssignPrestring,
+		annotation.DeepAssignPrimitivePrestring,
+		annotation.ParamAssignDeepPrestring,
+		annotation.FuncRetAssignDeepPrestring,
+		annotation.VariadicParamAssignDeepPrestring,
+		annotation.FieldAssignDeepPrestring,
+		annotation.GlobalVarAssignDeepPrestring,
+		annotation.LocalVarAssignDeepPrestring,
+		annotation.ChanSendPrestring:
+		return CategoryDeepAssign
+	default:
+		return CategoryOther
+	}
+}
+
+// isDeepRead returns true iff the producer prestring describes a nilable value read from a
+// container (e.g., an element of a map, slice, or channel), possibly lacking guarding.
+func isDeepRead(producer annotation.Prestring) bool {
+	if l, ok := producer.(annotation.LocatedPrestring); ok {
+		producer = l.Contained
+	}
+	switch producer.(type) {
+	case annotation.MapReadPrestring,
+		annotation.ArrayReadPrestring,
+		annotation.SliceReadPrestring,
+		annotation.PtrReadPrestring,
+		annotation.ChanRecvPrestring,
+		annotation.MethodRecvDeepPrestring,
+		annotation.FuncParamDeepPrestring,
+		annotation.VariadicFuncParamDeepPrestring,
+		annotation.FuncReturnDeepPrestring,
+		annotation.FldReadDeepPrestring,
+		annotation.LocalVarReadDeepPrestring,
+		annotation.GlobalVarReadDeepPrestring,
+		annotation.GuardMissingPrestring:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,57 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnostic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/nilaway/config"
+)
+
+func TestCategories(t *testing.T) {
+	t.Parallel()
+
+	// All categories must be listed in config.Categories to be accepted by the category
+	// configurations.
+	categories := []Category{
+		CategoryPtrLoad,
+		CategoryFieldAccess,
+		CategoryMapAccess,
+		CategoryMapWrite,
+		CategorySliceAccess,
+		CategoryFuncValueCall,
+		CategoryTypedNilInterface,
+		CategoryNilReceiver,
+		CategoryErrorResult,
+		CategoryFieldAssign,
+		CategoryGlobalAssign,
+		CategoryArgPass,
+		CategoryNilableReturn,
+		CategoryInterfaceCovariance,
+		CategoryDeepAssign,
+		CategoryDeepRead,
+		CategoryOther,
+	}
+	require.Len(t, config.Categories, len(categories))
+	for _, c := range categories {
+		require.Contains(t, config.Categories, string(c))
+	}
+}
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
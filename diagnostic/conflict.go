@@ -30,6 +30,10 @@ type conflict struct {
 	position token.Position
 	// flow stores nil flow from source to dereference point
 	flow nilFlow
+	// category is the stable code of the class of this conflict.
+	category Category
+	// downgraded indicates whether this conflict should be reported as a warning instead of an error.
+	downgraded bool
 	// similarConflicts stores other conflicts that are similar to this one.
 	similarConflicts []*conflict
 }
@@ -53,8 +57,13 @@ func (c *conflict) String() string {
 			"other place(s): %s.)", len(c.similarConflicts), posString)
 	}
 
-	return fmt.Sprintf("Potential nil panic detected. Observed nil flow from "+
-		"source to dereference point: %s%s\n", c.flow.String(), similarConflictsString)
+	downgradedString := ""
+	if c.downgraded {
+		downgradedString = " (downgraded to warning)"
+	}
+
+	return fmt.Sprintf("Potential nil panic detected [%s]%s. Observed nil flow from "+
+		"source to dereference point: %s%s\n", c.category, downgradedString, c.flow.String(), similarConflictsString)
 }
 
 func (c *conflict) addSimilarConflict(conflict conflict) {
@@ -120,6 +129,11 @@ func groupConflicts(allConflicts []conflict, pass *analysis.Pass, cwd string) []
 			}
 		}
 
+		// Downgraded conflicts are reported differently, so they should never be grouped with the others.
+		if c.downgraded {
+			key = "downgraded:" + key
+		}
+
 		if existingConflictIndex, ok := conflictsMap[key]; ok {
 			// Grouping condition satisfied. Add new conflict to `similarConflicts` in `existingConflict`, and update groupedConflicts map
 			allConflicts[existingConflictIndex].addSimilarConflict(c)
@@ -21,11 +21,14 @@ import (
 	"cmp"
 	"fmt"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
 
 	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/inference"
 	"go.uber.org/nilaway/util"
 	"golang.org/x/tools/go/analysis"
@@ -49,6 +52,9 @@ type Engine struct {
 	// cwd is the current working directory for trimming the file names to get truly package- and
 	// build-system- (bazel for example adds a random sandbox prefix) independent positions.
 	cwd string
+	// pkgOf resolves the package where a conflict is reported, for applying the package-specific
+	// category configurations. It is nil if all conflicts are reported in the current package.
+	pkgOf func(token.Pos) *types.Package
 }
 
 // NewEngine creates a new diagnostic engine.
@@ -96,12 +102,34 @@ func NewEngine(pass *analysis.Pass) *Engine {
 	return &Engine{pass: pass, files: files, cwd: cwd}
 }
 
+// SetPackageResolver sets the function that resolves the package where a conflict is reported,
+// for the engines that report conflicts in multiple packages (e.g., the one of a global inference
+// pass without a current package).
+func (e *Engine) SetPackageResolver(pkgOf func(token.Pos) *types.Package) {
+	e.pkgOf = pkgOf
+}
+
 // Diagnostics generates diagnostics from the internally-stored conflicts. The grouping parameter
 // controls whether the conflicts with the same nil flow -- the part in the complete nil flow going
 // from a nilable source point to the conflict point -- are grouped together (under the first
-// diagnostic) for concise reporting. The returned slice of diagnostics are sorted by file names
-// and then offsets in the file.
+// diagnostic) for concise reporting. The conflicts whose categories are disabled for the current
+// package are dropped, and the ones whose categories are downgraded are marked as such. The
+// returned slice of diagnostics are sorted by file names and then offsets in the file.
 func (e *Engine) Diagnostics(grouping bool) []analysis.Diagnostic {
+	// Apply the category configurations before grouping such that the dropped conflicts do not
+	// hide the others.
+	conf := e.pass.ResultOf[config.Analyzer].(*config.Config)
+	pkgOf := func(token.Position) *types.Package { return e.pass.Pkg }
+	if e.pkgOf != nil {
+		pkgOf = func(position token.Position) *types.Package { return e.pkgOf(e.toPos(position)) }
+	}
+	e.conflicts = slices.DeleteFunc(e.conflicts, func(c conflict) bool {
+		return conf.IsCategoryDisabled(string(c.category), pkgOf(c.position))
+	})
+	for i := range e.conflicts {
+		e.conflicts[i].downgraded = conf.IsCategoryDowngraded(string(e.conflicts[i].category), pkgOf(e.conflicts[i].position))
+	}
+
 	// First sort the conflicts by position such that similar conflicts are grouped under the
 	// first diagnostic.
 	slices.SortFunc(e.conflicts, func(a, b conflict) int {
@@ -117,17 +145,54 @@ func (e *Engine) Diagnostics(grouping bool) []analysis.Diagnostic {
 		conflicts = groupConflicts(e.conflicts, e.pass, e.cwd)
 	}
 
-	// Build diagnostics from conflicts.
+	// Build diagnostics from conflicts. The nodes of the nil flow are also attached as the related
+	// information of the diagnostics for the drivers (or tools) that consume structured outputs.
 	diagnostics := make([]analysis.Diagnostic, 0, len(conflicts))
 	for _, c := range conflicts {
+		var related []analysis.RelatedInformation
+		for _, n := range append(slices.Clip(c.flow.nilPath), c.flow.nonnilPath...) {
+			related = append(related, analysis.RelatedInformation{Pos: e.flowPos(n.consumerPosition), Message: n.reason()})
+		}
 		diagnostics = append(diagnostics, analysis.Diagnostic{
-			Pos:     e.toPos(c.position),
-			Message: c.String(),
+			Pos:      e.toPos(c.position),
+			Category: string(c.category),
+			Message:  c.String(),
+			Related:  related,
 		})
 	}
 	return diagnostics
 }
 
+// flowPos converts the position of a node in the nil flow, whose file name is truncated for
+// printing (see util.TruncatePosition), back to a token.Pos in the Fset. Different from toPos, it
+// does not create fake files, and token.NoPos is returned if the position cannot be uniquely
+// resolved.
+func (e *Engine) flowPos(position token.Position) token.Pos {
+	if !position.IsValid() {
+		return token.NoPos
+	}
+
+	var found *fileInfo
+	for name, info := range e.files {
+		if name != position.Filename && !strings.HasSuffix(name, "/"+position.Filename) {
+			continue
+		}
+		if found != nil {
+			// Ambiguous file name.
+			return token.NoPos
+		}
+		info := info
+		found = &info
+	}
+	if found == nil || position.Line > found.file.LineCount() || position.Offset > found.file.Size() {
+		return token.NoPos
+	}
+	if found.isFake {
+		return found.file.LineStart(position.Line)
+	}
+	return found.file.Pos(position.Offset)
+}
+
 // AddSingleAssertionConflict adds a new single assertion conflict to the engine.
 func (e *Engine) AddSingleAssertionConflict(trigger annotation.FullTrigger) {
 	producer, consumer := trigger.Prestrings(e.pass)
@@ -144,12 +209,16 @@ func (e *Engine) AddSingleAssertionConflict(trigger annotation.FullTrigger) {
 	e.conflicts = append(e.conflicts, conflict{
 		position: position,
 		flow:     flow,
+		category: categoryOf(producer, consumer),
 	})
 }
 
 // AddOverconstraintConflict adds a new overconstraint conflict to the engine.
 func (e *Engine) AddOverconstraintConflict(nilReason, nonnilReason inference.ExplainedBool) {
 	flow := nilFlow{}
+	// source and sink are the producer at the nil source and the consumer at the dereference point,
+	// respectively, for determining the category of the conflict.
+	var source, sink annotation.Prestring
 
 	// Build nil path by traversing the inference graph from `nilReason` part of the overconstraint failure.
 	// (Note that this traversal gives us a backward path from point of conflict to the source of nilability. Hence, we
@@ -161,7 +230,9 @@ func (e *Engine) AddOverconstraintConflict(nilReason, nonnilReason inference.Exp
 		// 2: Annotation present (i.e., no inference): we construct the reason from the annotation string
 		if producer != nil && consumer != nil {
 			flow.addNilPathNode(producer, consumer)
+			source = producer
 		} else {
+			source = nil
 			flow.addNilPathNode(annotation.LocatedPrestring{
 				Contained: r,
 				Location:  util.TruncatePosition(r.Position()),
@@ -185,6 +256,7 @@ func (e *Engine) AddOverconstraintConflict(nilReason, nonnilReason inference.Exp
 		if producer != nil && consumer != nil {
 			flow.addNonNilPathNode(producer, consumer)
 			reportPosition = position
+			sink = consumer
 		} else {
 			flow.addNonNilPathNode(annotation.LocatedPrestring{
 				Contained: r,
@@ -197,6 +269,7 @@ func (e *Engine) AddOverconstraintConflict(nilReason, nonnilReason inference.Exp
 	e.conflicts = append(e.conflicts, conflict{
 		position: reportPosition,
 		flow:     flow,
+		category: categoryOf(source, sink),
 	})
 }
 
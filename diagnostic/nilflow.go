@@ -91,11 +91,15 @@ func newNode(p annotation.Prestring, c annotation.Prestring) node {
 
 func (n *node) String() string {
 	posStr := "<no pos info>"
-	reasonStr := ""
 	if n.consumerPosition.IsValid() {
 		posStr = n.consumerPosition.String()
 	}
+	return fmt.Sprintf("\t- %s: %s", posStr, n.reason())
+}
 
+// reason returns the description of the node, i.e., why the value is nilable at this node.
+func (n *node) reason() string {
+	reasonStr := ""
 	if len(n.producerRepr) > 0 {
 		reasonStr += n.producerRepr
 	}
@@ -105,8 +109,7 @@ func (n *node) String() string {
 		}
 		reasonStr += n.consumerRepr
 	}
-
-	return fmt.Sprintf("\t- %s: %s", posStr, reasonStr)
+	return reasonStr
 }
 
 func pathString(nodes []node) string {
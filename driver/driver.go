@@ -0,0 +1,549 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package driver implements a whole-program driver for NilAway: it loads all packages of a program
+// (including their dependencies) with go/packages, and runs NilAway's analyzers in-process in
+// dependency order. Unlike the standard drivers, it can additionally perform a global inference
+// pass over the combined implication graph of all analyzed packages, which serves as a reference
+// to compare the modular results against and reports the conflicts that span sibling packages
+// (i.e., packages that no analyzed package imports together).
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"go.uber.org/nilaway/accumulation"
+	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion/function"
+	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/diagnostic"
+	"go.uber.org/nilaway/inference"
+	"go.uber.org/nilaway/util/analysishelper"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// Options configures a whole-program run of NilAway.
+type Options struct {
+	// Dir is the directory in which to load the packages, empty means the current directory.
+	Dir string
+	// Env is the environment for loading the packages, nil means the current environment.
+	Env []string
+	// Tests indicates whether the test packages should be loaded and analyzed as well.
+	Tests bool
+	// Overlay maps the absolute file paths to the contents that replace the ones on disk (see
+	// packages.Config.Overlay), e.g., for analyzing modified sources without writing them.
+	Overlay map[string][]byte
+	// Global enables the global inference pass over the combined implication graph of all
+	// analyzed packages after the modular analysis (see Result.GlobalDiagnostics).
+	Global bool
+}
+
+// Diagnostic is a diagnostic reported on a root package.
+type Diagnostic struct {
+	analysis.Diagnostic
+	// Package is the root package that the diagnostic is reported on.
+	Package *packages.Package
+}
+
+// Result is the result of a whole-program run of NilAway.
+type Result struct {
+	// Fset is the file set of all loaded packages, which resolves the positions of the diagnostics.
+	Fset *token.FileSet
+	// Config is the NilAway configuration of the run, e.g., for formatting the diagnostics.
+	Config *config.Config
+	// Diagnostics are the diagnostics reported by the modular analysis of the root packages, i.e.,
+	// the same diagnostics that the standard drivers would report.
+	Diagnostics []Diagnostic
+	// GlobalDiagnostics are the diagnostics on the root packages that are reported by the global
+	// inference pass but not by the modular analysis, which is only available if Options.Global is
+	// set. The modular analysis of a package only sees the facts from its dependencies, so these
+	// are the conflicts between the nilabilities of a site determined by sibling packages.
+	GlobalDiagnostics []Diagnostic
+	// Annotations are the diagnostics for the inferred annotations of the exported API of the root
+	// packages, which are only computed if requested (see accumulation.Result.Annotations).
+	Annotations []Diagnostic
+	// Stats maps the ID of each root package to the statistics of its analysis.
+	Stats map[string]accumulation.Stats
+	// SoftErrors are the soft type errors (e.g., unused variables or imports, see types.Error)
+	// in the root package, which are tolerated by Program.Analyze since they do not affect the
+	// analysis.
+	SoftErrors []types.Error
+	// Triggers are the full triggers created by the function analyzer for the root package, which
+	// are only available from Program.Analyze (e.g., for checking the determinism of the analysis).
+	Triggers []annotation.FullTrigger
+}
+
+// Run loads the packages matching the patterns along with all their dependencies, and runs
+// NilAway on them in dependency order. The diagnostics are only reported on the root packages
+// (i.e., the ones matching the patterns), while the dependencies are analyzed for the facts. Note
+// that NilAway is configured by the flags of config.Analyzer, as in the standard drivers (e.g.,
+// config.IncludePkgsFlag limits the packages to be analyzed).
+func Run(ctx context.Context, patterns []string, opts Options) (*Result, error) {
+	roots, pkgs, err := load(ctx, patterns, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r := newRunner(pkgs)
+	result := &Result{Fset: roots[0].Fset, Stats: make(map[string]accumulation.Stats, len(roots))}
+	isRoot := make(map[*packages.Package]bool, len(roots))
+	for _, pkg := range roots {
+		isRoot[pkg] = true
+	}
+	for _, pkg := range pkgs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		results, err := r.runPackage(pkg)
+		if err != nil {
+			return nil, fmt.Errorf("analyze package %q: %w", pkg.ID, err)
+		}
+		if !isRoot[pkg] {
+			continue
+		}
+		result.collect(pkg, results)
+	}
+
+	if opts.Global {
+		result.GlobalDiagnostics = r.runGlobal(result.Config, roots, result.Diagnostics)
+	}
+	return result, nil
+}
+
+// load loads the packages matching the patterns, and returns the root packages and all packages
+// (including the dependencies) in dependency order.
+func load(ctx context.Context, patterns []string, opts Options) (roots, pkgs []*packages.Package, _ error) {
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps |
+			packages.NeedTypes | packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo,
+		Dir:     opts.Dir,
+		Env:     opts.Env,
+		Tests:   opts.Tests,
+		Overlay: opts.Overlay,
+	}
+	roots, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load packages: %w", err)
+	}
+	if len(roots) == 0 {
+		return nil, nil, fmt.Errorf("no packages matching %q", patterns)
+	}
+
+	// Collect all packages in dependency order (i.e., post order), along with any errors.
+	var errs []error
+	packages.Visit(roots, nil, func(pkg *packages.Package) {
+		pkgs = append(pkgs, pkg)
+		for _, e := range pkg.Errors {
+			errs = append(errs, e)
+		}
+	})
+	if len(errs) > 0 {
+		return nil, nil, fmt.Errorf("load packages: %w", errors.Join(errs...))
+	}
+	return roots, pkgs, nil
+}
+
+// Program is a loaded program with a single root package, whose dependencies are analyzed only
+// once on loading. The root package can then be analyzed repeatedly with modified sources (e.g.,
+// for minimizing reproducers, see package repro) without re-analyzing the dependencies.
+type Program struct {
+	runner *runner
+	root   *packages.Package
+}
+
+// Load loads the single package matching the pattern along with all its dependencies, and runs
+// NilAway on the dependencies in dependency order (see Run).
+func Load(ctx context.Context, pattern string, opts Options) (*Program, error) {
+	roots, pkgs, err := load(ctx, []string{pattern}, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) != 1 {
+		return nil, fmt.Errorf("pattern %q matches %d packages, expected exactly one", pattern, len(roots))
+	}
+
+	r := newRunner(pkgs)
+	for _, pkg := range pkgs {
+		if pkg == roots[0] {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if _, err := r.runPackage(pkg); err != nil {
+			return nil, fmt.Errorf("analyze package %q: %w", pkg.ID, err)
+		}
+	}
+	return &Program{runner: r, root: roots[0]}, nil
+}
+
+// Root returns the root package as loaded from disk.
+func (p *Program) Root() *packages.Package {
+	return p.root
+}
+
+// ErrTypeCheck is returned by Program.Analyze if the root package fails to parse or type check.
+var ErrTypeCheck = errors.New("root package does not type check")
+
+// Analyze parses and type checks the root package with the overlay (i.e., the contents replacing
+// the ones of its files on disk), and runs NilAway on it. Note that the overlay can only modify
+// the existing files of the root package, and its imports must be a subset of the original ones.
+// The soft type errors are tolerated and returned in Result.SoftErrors.
+func (p *Program) Analyze(overlay map[string][]byte) (*Result, error) {
+	fset := p.root.Fset
+	files := make([]*ast.File, 0, len(p.root.GoFiles))
+	for _, name := range p.root.GoFiles {
+		var src any
+		if content, ok := overlay[name]; ok {
+			src = content
+		}
+		file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("%w: parse file %q: %w", ErrTypeCheck, name, err)
+		}
+		files = append(files, file)
+	}
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Instances:  make(map[*ast.Ident]types.Instance),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	var (
+		softErrs []types.Error
+		hardErr  error
+	)
+	conf := &types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if imp, ok := p.root.Imports[path]; ok {
+				return imp.Types, nil
+			}
+			return nil, fmt.Errorf("package %q is not imported by the original package", path)
+		}),
+		Sizes: p.root.TypesSizes,
+		Error: func(err error) {
+			if e, ok := err.(types.Error); ok && e.Soft {
+				softErrs = append(softErrs, e)
+			} else if hardErr == nil {
+				hardErr = err
+			}
+		},
+	}
+	// The error returned by Check is the first one (which may be soft), so we check the first hard
+	// error recorded instead.
+	typesPkg, _ := conf.Check(p.root.PkgPath, fset, files, info)
+	if hardErr != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTypeCheck, hardErr)
+	}
+
+	pkg := &packages.Package{
+		ID:         p.root.ID,
+		Name:       p.root.Name,
+		PkgPath:    p.root.PkgPath,
+		Fset:       fset,
+		GoFiles:    p.root.GoFiles,
+		OtherFiles: p.root.OtherFiles,
+		Syntax:     files,
+		Types:      typesPkg,
+		TypesInfo:  info,
+		TypesSizes: p.root.TypesSizes,
+		Imports:    p.root.Imports,
+	}
+
+	// The dependencies are the same as the original root package, except for the root itself. The
+	// states of the current analysis are removed afterward since the package is never reused.
+	r := p.runner
+	deps := []*types.Package{typesPkg}
+	for _, d := range r.deps[p.root.Types] {
+		if d != p.root.Types {
+			deps = append(deps, d)
+		}
+	}
+	r.deps[typesPkg] = deps
+	defer func() {
+		delete(r.deps, typesPkg)
+		for k := range r.facts {
+			if k.pkg == typesPkg || (k.obj != nil && k.obj.Pkg() == typesPkg) {
+				delete(r.facts, k)
+			}
+		}
+	}()
+
+	results, err := r.runPackage(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("analyze package %q: %w", pkg.ID, err)
+	}
+	result := &Result{Fset: fset, Stats: make(map[string]accumulation.Stats, 1), SoftErrors: softErrs}
+	result.collect(pkg, results)
+	if res := results[function.Analyzer].(*analysishelper.Result[*function.Result]); res.Res != nil {
+		result.Triggers = res.Res.Triggers
+	}
+	return result, nil
+}
+
+// collect adds the results of the analyzers on the root package to the result.
+func (r *Result) collect(pkg *packages.Package, results map[*analysis.Analyzer]any) {
+	r.Config = results[config.Analyzer].(*config.Config)
+	res := results[accumulation.Analyzer].(*accumulation.Result)
+	for _, d := range res.Diagnostics {
+		r.Diagnostics = append(r.Diagnostics, Diagnostic{Diagnostic: d, Package: pkg})
+	}
+	for _, d := range res.Annotations {
+		r.Annotations = append(r.Annotations, Diagnostic{Diagnostic: d, Package: pkg})
+	}
+	r.Stats[pkg.ID] = res.Stats
+}
+
+// importerFunc implements types.Importer with a function.
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// factKey is the key of a fact in the fact store. Exactly one of pkg and obj is non-nil,
+// depending on whether the fact is a package fact or an object fact.
+type factKey struct {
+	analyzer *analysis.Analyzer
+	pkg      *types.Package
+	obj      types.Object
+	typ      reflect.Type
+}
+
+// runner runs the analyzers on the packages in-process. The facts are directly shared in memory
+// (instead of being serialized) since all packages are analyzed in the same universe of types.
+type runner struct {
+	// analyzers are the analyzers to run on each package, in the order of their requirements.
+	analyzers []*analysis.Analyzer
+	// facts stores the facts exported by all analyzed packages.
+	facts map[factKey]analysis.Fact
+	// deps maps each package to its transitive dependencies (including itself) sorted by their
+	// paths, which determines the facts visible in its analysis.
+	deps map[*types.Package][]*types.Package
+}
+
+func newRunner(pkgs []*packages.Package) *runner {
+	var (
+		analyzers []*analysis.Analyzer
+		visit     func(a *analysis.Analyzer)
+	)
+	visited := make(map[*analysis.Analyzer]bool)
+	visit = func(a *analysis.Analyzer) {
+		if visited[a] {
+			return
+		}
+		visited[a] = true
+		for _, req := range a.Requires {
+			visit(req)
+		}
+		analyzers = append(analyzers, a)
+	}
+	visit(accumulation.Analyzer)
+
+	// The packages are in dependency order, so the dependencies of the imports are always ready.
+	deps := make(map[*types.Package][]*types.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		set := map[*types.Package]bool{pkg.Types: true}
+		for _, imp := range pkg.Imports {
+			for _, d := range deps[imp.Types] {
+				set[d] = true
+			}
+		}
+		list := make([]*types.Package, 0, len(set))
+		for d := range set {
+			list = append(list, d)
+		}
+		slices.SortFunc(list, func(a, b *types.Package) int { return strings.Compare(a.Path(), b.Path()) })
+		deps[pkg.Types] = list
+	}
+
+	return &runner{analyzers: analyzers, facts: make(map[factKey]analysis.Fact), deps: deps}
+}
+
+// runPackage runs all analyzers on the package, and returns their results.
+func (r *runner) runPackage(pkg *packages.Package) (_ map[*analysis.Analyzer]any, err error) {
+	// The NilAway analyzers recover from their own panics, but we still guard against the panics
+	// from the others (e.g., buildssa).
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("INTERNAL PANIC: %s\n%s", p, string(debug.Stack()))
+		}
+	}()
+
+	visible := make(map[*types.Package]bool, len(r.deps[pkg.Types]))
+	for _, d := range r.deps[pkg.Types] {
+		visible[d] = true
+	}
+
+	results := make(map[*analysis.Analyzer]any, len(r.analyzers))
+	for _, a := range r.analyzers {
+		resultOf := make(map[*analysis.Analyzer]any, len(a.Requires))
+		for _, req := range a.Requires {
+			resultOf[req] = results[req]
+		}
+		a := a
+		pass := &analysis.Pass{
+			Analyzer:     a,
+			Fset:         pkg.Fset,
+			Files:        pkg.Syntax,
+			OtherFiles:   pkg.OtherFiles,
+			IgnoredFiles: pkg.IgnoredFiles,
+			Pkg:          pkg.Types,
+			TypesInfo:    pkg.TypesInfo,
+			TypesSizes:   pkg.TypesSizes,
+			TypeErrors:   pkg.TypeErrors,
+			ResultOf:     resultOf,
+			// The analyzers required by NilAway do not report diagnostics via the pass.
+			Report: func(analysis.Diagnostic) {},
+			ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
+				if obj == nil || !visible[obj.Pkg()] {
+					return false
+				}
+				return r.importFact(factKey{analyzer: a, obj: obj, typ: reflect.TypeOf(fact)}, fact)
+			},
+			ImportPackageFact: func(p *types.Package, fact analysis.Fact) bool {
+				if !visible[p] {
+					return false
+				}
+				return r.importFact(factKey{analyzer: a, pkg: p, typ: reflect.TypeOf(fact)}, fact)
+			},
+			ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+				if obj.Pkg() != pkg.Types {
+					panic(fmt.Sprintf("analyzer %q exported a fact for object %v outside of package %q", a.Name, obj, pkg.Types.Path()))
+				}
+				r.facts[factKey{analyzer: a, obj: obj, typ: reflect.TypeOf(fact)}] = fact
+			},
+			ExportPackageFact: func(fact analysis.Fact) {
+				r.facts[factKey{analyzer: a, pkg: pkg.Types, typ: reflect.TypeOf(fact)}] = fact
+			},
+			AllPackageFacts: func() []analysis.PackageFact {
+				return r.allPackageFacts(a, r.deps[pkg.Types])
+			},
+			AllObjectFacts: func() []analysis.ObjectFact {
+				var facts []analysis.ObjectFact
+				for k, f := range r.facts {
+					if k.analyzer == a && k.obj != nil && visible[k.obj.Pkg()] {
+						facts = append(facts, analysis.ObjectFact{Object: k.obj, Fact: f})
+					}
+				}
+				return facts
+			},
+		}
+
+		res, err := a.Run(pass)
+		if err != nil {
+			return nil, fmt.Errorf("analyzer %q: %w", a.Name, err)
+		}
+		results[a] = res
+	}
+	return results, nil
+}
+
+// importFact copies the stored fact (if any) into the passed fact, which is the same as what the
+// standard drivers do.
+func (r *runner) importFact(key factKey, fact analysis.Fact) bool {
+	stored, ok := r.facts[key]
+	if !ok {
+		return false
+	}
+	reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(stored).Elem())
+	return true
+}
+
+// allPackageFacts returns the package facts exported by the analyzer for the passed packages.
+func (r *runner) allPackageFacts(a *analysis.Analyzer, pkgs []*types.Package) []analysis.PackageFact {
+	var facts []analysis.PackageFact
+	for _, p := range pkgs {
+		for _, t := range a.FactTypes {
+			if f, ok := r.facts[factKey{analyzer: a, pkg: p, typ: reflect.TypeOf(t)}]; ok {
+				facts = append(facts, analysis.PackageFact{Package: p, Fact: f})
+			}
+		}
+	}
+	return facts
+}
+
+// runGlobal runs the global inference pass, which observes the inferred maps exported by _all_
+// analyzed packages in a single inference engine. Since each exported map contains all
+// information of its package that is visible to the other packages, this is equivalent to
+// running inference over the combined implication graph. It returns the diagnostics on the root
+// packages that are not already in the passed modular diagnostics.
+func (r *runner) runGlobal(conf *config.Config, roots []*packages.Package, modular []Diagnostic) []Diagnostic {
+	var (
+		all   []*types.Package
+		files = make(map[string]*packages.Package)
+		pass  = &analysis.Pass{
+			Analyzer: accumulation.Analyzer,
+			Fset:     roots[0].Fset,
+			ResultOf: map[*analysis.Analyzer]any{config.Analyzer: conf},
+			Report:   func(analysis.Diagnostic) {},
+		}
+	)
+	for p := range r.deps {
+		all = append(all, p)
+	}
+	slices.SortFunc(all, func(a, b *types.Package) int { return strings.Compare(a.Path(), b.Path()) })
+	pass.AllPackageFacts = func() []analysis.PackageFact { return r.allPackageFacts(accumulation.Analyzer, all) }
+	for _, pkg := range roots {
+		pass.Files = append(pass.Files, pkg.Syntax...)
+		for _, f := range pkg.Syntax {
+			files[pass.Fset.File(f.Pos()).Name()] = pkg
+		}
+	}
+
+	diagnosticEngine := diagnostic.NewEngine(pass)
+	// The global pass does not have a package, so the package-specific category configurations
+	// are applied by the packages of the files where the conflicts are reported.
+	diagnosticEngine.SetPackageResolver(func(pos token.Pos) *types.Package {
+		if pkg, ok := files[pass.Fset.Position(pos).Filename]; ok {
+			return pkg.Types
+		}
+		return nil
+	})
+	inferenceEngine := inference.NewEngine(pass, diagnosticEngine)
+	// All facts are produced in the same run, so they are never incompatible.
+	_ = inferenceEngine.ObserveUpstream()
+
+	type key struct {
+		pos token.Pos
+		msg string
+	}
+	reported := make(map[key]bool, len(modular))
+	for _, d := range modular {
+		reported[key{d.Pos, d.Message}] = true
+	}
+	var diagnostics []Diagnostic
+	for _, d := range diagnosticEngine.Diagnostics(conf.GroupErrorMessages) {
+		pkg, ok := files[pass.Fset.Position(d.Pos).Filename]
+		if !ok || reported[key{d.Pos, d.Message}] {
+			continue
+		}
+		diagnostics = append(diagnostics, Diagnostic{Diagnostic: d, Package: pkg})
+	}
+	return diagnostics
+}
@@ -0,0 +1,74 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	// Load the packages in GOPATH mode from the testdata directory of NilAway.
+	gopath, err := filepath.Abs(filepath.Join("..", "testdata"))
+	require.NoError(t, err)
+	env := append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off", "GOPROXY=off")
+
+	// lineOf returns the source line of the diagnostic for checking the expectation comments.
+	lineOf := func(res *Result, d Diagnostic) string {
+		position := res.Fset.Position(d.Pos)
+		content, err := os.ReadFile(position.Filename)
+		require.NoError(t, err)
+		return strings.Split(string(content), "\n")[position.Line-1]
+	}
+
+	for _, global := range []bool{false, true} {
+		res, err := Run(context.Background(), []string{"go.uber.org/wholeprogram/..."}, Options{Env: env, Global: global})
+		require.NoError(t, err)
+
+		require.Len(t, res.Diagnostics, 1)
+		require.Equal(t, "go.uber.org/wholeprogram/reader", res.Diagnostics[0].Package.PkgPath)
+		require.Contains(t, lineOf(res, res.Diagnostics[0]), "reported by the modular analysis")
+
+		if !global {
+			require.Empty(t, res.GlobalDiagnostics)
+			continue
+		}
+		// The conflict on the field of upstream.S spans the sibling packages writer and reader, so
+		// only the global pass can find it.
+		require.Len(t, res.GlobalDiagnostics, 1)
+		require.Equal(t, "go.uber.org/wholeprogram/reader", res.GlobalDiagnostics[0].Package.PkgPath)
+		require.Contains(t, lineOf(res, res.GlobalDiagnostics[0]), "reported by the global pass only")
+		require.Contains(t, res.GlobalDiagnostics[0].Message, "writer.go")
+	}
+}
+
+func TestRun_LoadError(t *testing.T) {
+	t.Parallel()
+
+	_, err := Run(context.Background(), []string{"./does/not/exist"}, Options{})
+	require.Error(t, err)
+}
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
@@ -0,0 +1,128 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// FuzzAnalyze runs NilAway on mutated Go programs (in place of the template package
+// go.uber.org/fuzz), and checks that NilAway never panics internally and that its diagnostics and
+// the full triggers of the function analyzer are deterministic across runs. The inputs that do not
+// parse or type check are skipped.
+func FuzzAnalyze(f *testing.F) {
+	for _, src := range []string{
+		// Unusual control flow.
+		`package fuzz
+func f(p *int, n int) int {
+loop:
+	for i := 0; i < n; i++ {
+		switch {
+		case i == 1:
+			continue loop
+		case i == 2:
+			goto end
+		default:
+			break loop
+		}
+	}
+end:
+	select {}
+	return *p
+}`,
+		// Closures, defers, and recovers.
+		`package fuzz
+func f() (p *int) {
+	defer func() {
+		if r := recover(); r != nil {
+			p = nil
+		}
+	}()
+	g := func() *int { return p }
+	return g()
+}`,
+		// Type switches, channels, maps, and variadic functions.
+		`package fuzz
+func f(x any, c chan *int, m map[string]*int, vs ...*int) int {
+	switch v := x.(type) {
+	case *int:
+		return *v
+	case nil:
+		return *<-c
+	}
+	if len(vs) > 0 {
+		return *vs[0] + *m[""]
+	}
+	return 0
+}`,
+		// Generics, method values, and struct fields.
+		`package fuzz
+type S[T any] struct{ f *T }
+func (s *S[T]) get() *T { return s.f }
+func f[T any](s *S[T]) T {
+	g := s.get
+	return *g()
+}`,
+		// Errors and contracts.
+		`package fuzz
+import "errors"
+// contract(nonnil -> nonnil)
+func g(p *int) *int { return p }
+func f(p *int) (*int, error) {
+	if p == nil {
+		return nil, errors.New("nil")
+	}
+	return g(p), nil
+}`,
+	} {
+		f.Add(src)
+	}
+
+	gopath, err := filepath.Abs(filepath.Join("..", "testdata"))
+	require.NoError(f, err)
+	env := append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off", "GOPROXY=off")
+	// The dependencies of the template package are analyzed only once for all inputs.
+	program, err := Load(context.Background(), "go.uber.org/fuzz", Options{Env: env})
+	require.NoError(f, err)
+	file := program.Root().GoFiles[0]
+
+	f.Fuzz(func(t *testing.T, src string) {
+		var runs, triggers [2][]string
+		for i := range runs {
+			res, err := program.Analyze(map[string][]byte{file: []byte(src)})
+			if errors.Is(err, ErrTypeCheck) {
+				t.Skip(err)
+			}
+			require.NoError(t, err, "source:\n%s", src)
+			for _, d := range res.Diagnostics {
+				require.NotContains(t, d.Message, "INTERNAL", "source:\n%s", src)
+				runs[i] = append(runs[i], fmt.Sprintf("%s: %s", res.Fset.Position(d.Pos), d.Message))
+			}
+			for _, trigger := range res.Triggers {
+				triggers[i] = append(triggers[i], fmt.Sprintf("%s: %s -> %s", res.Fset.Position(trigger.Pos()),
+					trigger.Producer.Annotation.Prestring(), trigger.Consumer.Annotation.Prestring()))
+			}
+		}
+		require.Equal(t, runs[0], runs[1], "non-deterministic diagnostics for source:\n%s", src)
+		require.Equal(t, triggers[0], triggers[1], "non-deterministic full triggers for source:\n%s", src)
+	})
+}
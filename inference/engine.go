@@ -20,7 +20,9 @@ import (
 	"cmp"
 	"encoding/gob"
 	"fmt"
+	"go/token"
 	"slices"
+	"strings"
 
 	"go.uber.org/nilaway/annotation"
 	"go.uber.org/nilaway/assertion/function/assertiontree"
@@ -53,6 +55,127 @@ type Engine struct {
 	// controls any triggers. This field is for internal use in the struct only and should not be
 	// accessed elsewhere.
 	controlledTriggersBySite map[primitiveSite]map[annotation.FullTrigger]bool
+	// otherExplanations stores, for each determined site, the distinct agreeing explanations
+	// observed after the one stored in the inferred map (up to _maxOtherExplanations), such that
+	// the flows reaching a site can be reported if the site turns out to be overconstrained.
+	otherExplanations map[primitiveSite][]ExplainedBool
+	// pendingNilable and pendingNonnil store the observed site explanations that have not yet
+	// been applied to the inferred map, see observeSiteExplanation and solve.
+	pendingNilable, pendingNonnil []siteObservation
+	// pendingNonnilImplications store the observed implications to a nonnil consumer from an
+	// undetermined producer, see observeImplication and solve.
+	pendingNonnilImplications []implicationObservation
+}
+
+// _maxOtherExplanations is the maximum number of the other explanations kept for a site (see
+// Engine.otherExplanations), which bounds the number of conflicts reported for an overconstrained
+// site, since a site reached by many flows (e.g., a widely used helper) would otherwise yield a
+// conflict for each of them.
+const _maxOtherExplanations = 8
+
+// siteObservation is an observed explanation of the nilability of a site.
+type siteObservation struct {
+	site        primitiveSite
+	explanation ExplainedBool
+}
+
+// implicationObservation is an observed implication from the producer site to the consumer site.
+type implicationObservation struct {
+	producer, consumer primitiveSite
+	assertion          primitiveFullTrigger
+}
+
+// compareSiteObservations defines the canonical order of the site observations for solve. The
+// order does not rely on the positions unless necessary (i.e., as the last resort to break ties),
+// such that reordering unrelated code does not change the order.
+func compareSiteObservations(a, b siteObservation) int {
+	if n := compareSites(a.site, b.site); n != 0 {
+		return n
+	}
+	return compareExplanations(a.explanation, b.explanation)
+}
+
+// compareSites compares two sites by their string representations first, then their positions.
+func compareSites(a, b primitiveSite) int {
+	if n := cmp.Compare(a.PkgPath, b.PkgPath); n != 0 {
+		return n
+	}
+	if n := cmp.Compare(a.Repr, b.Repr); n != 0 {
+		return n
+	}
+	if n := cmp.Compare(a.ObjectPath, b.ObjectPath); n != 0 {
+		return n
+	}
+	if a.IsDeep != b.IsDeep {
+		if a.IsDeep {
+			return 1
+		}
+		return -1
+	}
+	return comparePositions(a.Position, b.Position)
+}
+
+// compareExplanations compares two explanations by the representations of their triggers first,
+// then their positions, and recursively their deeper reasons.
+func compareExplanations(a, b ExplainedBool) int {
+	for ; a != nil && b != nil; a, b = a.DeeperReason(), b.DeeperReason() {
+		if a.Val() != b.Val() {
+			if a.Val() {
+				return 1
+			}
+			return -1
+		}
+		aProducer, aConsumer := a.TriggerReprs()
+		bProducer, bConsumer := b.TriggerReprs()
+		if n := cmp.Compare(stringOrEmpty(aProducer), stringOrEmpty(bProducer)); n != 0 {
+			return n
+		}
+		if n := cmp.Compare(stringOrEmpty(aConsumer), stringOrEmpty(bConsumer)); n != 0 {
+			return n
+		}
+		if n := comparePositions(a.Position(), b.Position()); n != 0 {
+			return n
+		}
+	}
+
+	// The shorter explanation goes first.
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// compareImplicationObservations defines the canonical order of the implication observations
+// for solve, similar to compareSiteObservations.
+func compareImplicationObservations(a, b implicationObservation) int {
+	if n := compareSites(a.producer, b.producer); n != 0 {
+		return n
+	}
+	if n := compareSites(a.consumer, b.consumer); n != 0 {
+		return n
+	}
+	return comparePositions(a.assertion.Position, b.assertion.Position)
+}
+
+func comparePositions(a, b token.Position) int {
+	if n := cmp.Compare(a.Filename, b.Filename); n != 0 {
+		return n
+	}
+	if n := cmp.Compare(a.Line, b.Line); n != 0 {
+		return n
+	}
+	return cmp.Compare(a.Column, b.Column)
+}
+
+func stringOrEmpty(s fmt.Stringer) string {
+	if s == nil {
+		return ""
+	}
+	return s.String()
 }
 
 // NewEngine constructs an inference engine that is ready to run inference.
@@ -63,6 +186,8 @@ func NewEngine(pass *analysis.Pass, diagnosticEngine conflictHandler) *Engine {
 		primitive:        primitive,
 		inferredMap:      newInferredMap(primitive),
 		diagnosticEngine: diagnosticEngine,
+
+		otherExplanations: make(map[primitiveSite][]ExplainedBool),
 	}
 }
 
@@ -79,19 +204,32 @@ func (e *Engine) InferredMap() *InferredMap {
 // As more information is observed through a call to ObservePackage, it will be
 // added to Mapping but not UpstreamMapping, then, on a call to Export, only the information
 // present in Mapping but not UpstreamMapping is exported to ensure minimization of output.
-func (e *Engine) ObserveUpstream() {
-	var facts []analysis.PackageFact
+//
+// The facts with an incompatible schema version (see FactSchemaVersion) are ignored, i.e., the
+// information from the corresponding upstream packages is treated as unknown, and a non-nil error
+// describing them is returned. The observation of the remaining facts is complete regardless.
+func (e *Engine) ObserveUpstream() error {
+	var (
+		facts        []analysis.PackageFact
+		incompatible []string
+	)
 	for _, packageFact := range e.pass.AllPackageFacts() {
 		// We only care about NilAway-related facts here.
-		if _, ok := packageFact.Fact.(*InferredMap); ok {
-			facts = append(facts, packageFact)
+		m, ok := packageFact.Fact.(*InferredMap)
+		if !ok {
+			continue
+		}
+		if m.schemaVersion != FactSchemaVersion {
+			incompatible = append(incompatible, fmt.Sprintf("%q (version %d)", packageFact.Package.Path(), m.schemaVersion))
+			continue
 		}
+		facts = append(facts, packageFact)
 	}
 
-	// `pass.AllPackageFacts()` returns the slice of package facts in _unspecified_ order. Here
-	// we sort the facts by package path to ensure deterministic iteration order, which is
-	// important for determinism in NilAway since our inference algorithm depends on the order of
-	// trigger / site nilability applications.
+	// `pass.AllPackageFacts()` returns the slice of package facts in _unspecified_ order. The
+	// inference results do not depend on the order of observations (see solve), but here we
+	// still sort the facts by package path such that the internal orders of the inferred map
+	// (and hence the exported facts) are deterministic.
 	slices.SortFunc(facts, func(i, j analysis.PackageFact) int {
 		return cmp.Compare(i.Package.Path(), j.Package.Path())
 	})
@@ -120,11 +258,22 @@ func (e *Engine) ObserveUpstream() {
 		})
 	}
 
+	e.solve()
+
 	// copy imported maps into upstreamMapping field
 	e.inferredMap.OrderedRange(func(site primitiveSite, val InferredVal) bool {
 		e.inferredMap.upstreamMapping[site] = val.copy()
 		return true
 	})
+
+	if len(incompatible) > 0 {
+		slices.Sort(incompatible)
+		return fmt.Errorf("ignored facts with incompatible schema versions (expected version %d) from "+
+			"upstream package(s) %s, likely stale facts produced by a different NilAway version (e.g., "+
+			"in a build cache); nilabilities from those packages are treated as unknown",
+			FactSchemaVersion, strings.Join(incompatible, ", "))
+	}
+	return nil
 }
 
 // ObserveAnnotations does one of two things. If the inferenceType is FullInfer, then it reads
@@ -139,10 +288,13 @@ func (e *Engine) ObserveAnnotations(pkgAnnotations *annotation.ObservedMap, mode
 		site := e.primitive.site(key, isDeep)
 		if val {
 			e.observeSiteExplanation(site, TrueBecauseAnnotation{AnnotationPos: site.Position})
+		} else if !isDeep && pkgAnnotations.IsConstructorInitialized(key) {
+			e.observeSiteExplanation(site, FalseBecauseConstructorInit{AnnotationPos: site.Position})
 		} else {
 			e.observeSiteExplanation(site, FalseBecauseAnnotation{AnnotationPos: site.Position})
 		}
 	}, mode != NoInfer)
+	e.solve()
 }
 
 // ObservePackage observes all the annotations and assertions computed locally about the current
@@ -261,6 +413,7 @@ func (e *Engine) buildPkgInferenceMap(triggers []annotation.FullTrigger) {
 		}
 		e.buildFromSingleFullTrigger(trigger)
 	}
+	e.solve()
 }
 
 func (e *Engine) buildFromSingleFullTrigger(trigger annotation.FullTrigger) {
@@ -311,12 +464,108 @@ func (e *Engine) buildFromSingleFullTrigger(trigger annotation.FullTrigger) {
 	}
 }
 
-// observeSiteExplanation augments inferred map with a definite value for the passed
-// site `site` - the definite value being given as the ExplainedBool `siteExplained`. Any conflicts
-// encountered during the inference are stored internally and will be available when the inferred
-// map is retrieved via `Engine.InferredMap`.There are three cases for what can happen when this
-// call is made. If the site is not already mapped to an InferredVal of any kind, then a mapping to
-// an DeterminedVal for the passed ExplainedBool is simply added - indicating that we now we have
+// observeSiteExplanation records a definite value for the passed site `site` - the definite
+// value being given as the ExplainedBool `siteExplained`. The observations are not applied to the
+// inferred map immediately, instead, they are kept pending until the next call to solve, such
+// that the inference results do not depend on the order of the observations.
+func (e *Engine) observeSiteExplanation(site primitiveSite, siteExplained ExplainedBool) {
+	o := siteObservation{site: site, explanation: siteExplained}
+	if siteExplained.Val() {
+		e.pendingNilable = append(e.pendingNilable, o)
+	} else {
+		e.pendingNonnil = append(e.pendingNonnil, o)
+	}
+}
+
+// solve applies all pending observations to the inferred map and computes the fixed point, in a
+// way that is independent of the order in which the observations (and the implications in the
+// graph) were made:
+//
+//   - First, the nilable values are propagated forward (i.e., via the implicates) from all pending
+//     nilable observations, in breadth-first order. This makes every site reachable from a nilable
+//     site nilable, which is the least fixed point regardless of the order. Note that determining
+//     a site to be nilable may activate controlled triggers, which may yield more observations
+//     and implications that are handled in the same manner.
+//   - Then, the pending implications from undetermined producers to nonnil consumers are
+//     resolved (see resolveNonnilImplication), such that a nil flow reaching a nonnil site always
+//     conflicts at the most downstream point, regardless of which end was determined first.
+//   - Finally, the nonnil values are propagated backward (i.e., via the implicants) from all
+//     pending nonnil observations. Since all sites reachable from nilable sites are already
+//     nilable, this can only yield conflicts at the sites of the nonnil observations themselves,
+//     i.e., exactly one conflict for each nonnil observation on a nilable site.
+//
+// Each step processes the observations level by level in a canonical order (see
+// compareSiteObservations), so that the explanation kept for each site is deterministic as well.
+// The identical observations in a level (e.g., from duplicated triggers) are only processed once,
+// such that they do not yield duplicate conflicts.
+func (e *Engine) solve() {
+	for len(e.pendingNilable) > 0 || len(e.pendingNonnilImplications) > 0 || len(e.pendingNonnil) > 0 {
+		for len(e.pendingNilable) > 0 {
+			level := e.pendingNilable
+			e.pendingNilable = nil
+			slices.SortFunc(level, compareSiteObservations)
+			level = slices.CompactFunc(level, func(a, b siteObservation) bool { return compareSiteObservations(a, b) == 0 })
+			for _, o := range level {
+				e.determineSite(o.site, o.explanation)
+			}
+		}
+		// The implications to nonnil consumers are resolved only after the nilable values are
+		// fully propagated: if the producer turns out to be nilable, the nilable value flows to
+		// the consumer (and conflicts there), such that the conflict is always reported at the
+		// most downstream point of the nil flow; otherwise, the producer must be nonnil.
+		if len(e.pendingNonnilImplications) > 0 {
+			implications := e.pendingNonnilImplications
+			e.pendingNonnilImplications = nil
+			slices.SortFunc(implications, compareImplicationObservations)
+			for _, i := range implications {
+				e.resolveNonnilImplication(i)
+			}
+			continue
+		}
+		// Determining sites to be nonnil never yields nilable observations, but we still only
+		// process one level at a time in case it ever does, such that the nilable values are
+		// always propagated first.
+		if len(e.pendingNonnil) > 0 {
+			level := e.pendingNonnil
+			e.pendingNonnil = nil
+			slices.SortFunc(level, compareSiteObservations)
+			level = slices.CompactFunc(level, func(a, b siteObservation) bool { return compareSiteObservations(a, b) == 0 })
+			for _, o := range level {
+				e.determineSite(o.site, o.explanation)
+			}
+		}
+	}
+}
+
+// resolveNonnilImplication resolves a pending implication to a nonnil consumer (see
+// observeImplication) after the nilable values are fully propagated: the nilable value of the
+// producer, if any, flows to the consumer, otherwise the producer is determined to be nonnil.
+func (e *Engine) resolveNonnilImplication(i implicationObservation) {
+	producer, _ := e.inferredMap.Load(i.producer)
+	if v, ok := producer.(*DeterminedVal); ok {
+		if v.Bool.Val() {
+			e.observeSiteExplanation(i.consumer, TrueBecauseDeepConstraint{
+				InternalAssertion: i.assertion,
+				DeeperExplanation: v.Bool,
+			})
+		}
+		return
+	}
+
+	// The consumer stays nonnil once determined, so it can be safely loaded here.
+	consumer, _ := e.inferredMap.Load(i.consumer)
+	e.observeSiteExplanation(i.producer, FalseBecauseDeepConstraint{
+		InternalAssertion: i.assertion,
+		DeeperExplanation: consumer.(*DeterminedVal).Bool,
+	})
+}
+
+// determineSite augments inferred map with a definite value for the passed site `site` - the
+// definite value being given as the ExplainedBool `siteExplained`. Any conflicts encountered
+// during the inference are stored internally and will be available when the inferred map is
+// retrieved via `Engine.InferredMap`. There are three cases for what can happen when this call is
+// made. If the site is not already mapped to an InferredVal of any kind, then a mapping to an
+// DeterminedVal for the passed ExplainedBool is simply added - indicating that we now we have
 // fixed the value of this site. If the site is already mapped to an DeterminedVal, then we check
 // if that ExplainedBool agrees with the passed one. If it does, then the call is a no-op. If it
 // does not, then we have discovered a site that is overconstrained to be both true and false by
@@ -324,10 +573,10 @@ func (e *Engine) buildFromSingleFullTrigger(trigger annotation.FullTrigger) {
 // internal failure list. Finally, if we discover that the site targeted by this call is currently
 // mapped to an UndeterminedVal then we update the mapping to a definite DeterminedVal in accordance
 // with the passed ExplainedBool, _and_ we walk the graph (forward if determining the site to be
-// true (nilable), backwards if determining the site to be false (nonnil)), recursively calling
-// observeSiteExplanation to determine all sites that must be determined from our knowledge of this
-// call in the context of the current implication graph.
-func (e *Engine) observeSiteExplanation(site primitiveSite, siteExplained ExplainedBool) {
+// true (nilable), backwards if determining the site to be false (nonnil)), observing the sites
+// that must be determined from our knowledge of this call in the context of the current
+// implication graph (which will be determined by the subsequent iterations in solve).
+func (e *Engine) determineSite(site primitiveSite, siteExplained ExplainedBool) {
 	val, ok := e.inferredMap.Load(site)
 	if !ok {
 		e.storeDeterminedAndActivateControlledTriggers(site, siteExplained)
@@ -346,17 +595,27 @@ func (e *Engine) observeSiteExplanation(site primitiveSite, siteExplained Explai
 	case *DeterminedVal:
 		if v.Bool.Val() == siteExplained.Val() {
 			// No-op if the site is already mapped to an DeterminedVal that agrees with the
-			// passed new value.
+			// passed new value, except that we keep the other explanations for reporting all
+			// flows reaching the site if it turns out to be overconstrained later. The other
+			// explanations are irrelevant if the site is annotated, though.
+			switch v.Bool.(type) {
+			case TrueBecauseAnnotation, FalseBecauseAnnotation:
+			default:
+				e.addOtherExplanation(site, v.Bool, siteExplained)
+			}
 			return
 		}
 
 		// Otherwise, this site is overconstrained to be both nilable and nonnil. We create an
-		// overconstrainedConflict and add it to the conflict list.
-		trueExplanation, falseExplanation := v.Bool, siteExplained
-		if !v.Bool.Val() {
-			trueExplanation, falseExplanation = falseExplanation, trueExplanation
+		// overconstrainedConflict for each flow that determined the site and add it to the
+		// conflict list.
+		for _, explanation := range append([]ExplainedBool{v.Bool}, e.otherExplanations[site]...) {
+			trueExplanation, falseExplanation := explanation, siteExplained
+			if !explanation.Val() {
+				trueExplanation, falseExplanation = falseExplanation, trueExplanation
+			}
+			e.diagnosticEngine.AddOverconstraintConflict(trueExplanation, falseExplanation)
 		}
-		e.diagnosticEngine.AddOverconstraintConflict(trueExplanation, falseExplanation)
 
 		// Even though we have a conflict, we still need to make sure to activate any controlled
 		// triggers that are waiting on this site, so that we would not miss processing any
@@ -388,6 +647,23 @@ func (e *Engine) observeSiteExplanation(site primitiveSite, siteExplained Explai
 	}
 }
 
+// addOtherExplanation keeps an explanation agreeing with the determined one of the site, unless
+// the same explanation (i.e., the same flow) is already kept or the site already has
+// _maxOtherExplanations of them. Since solve observes the explanations in a canonical order, the
+// kept explanations do not depend on the order of the observations.
+func (e *Engine) addOtherExplanation(site primitiveSite, determined, explanation ExplainedBool) {
+	others := e.otherExplanations[site]
+	if len(others) >= _maxOtherExplanations || compareExplanations(determined, explanation) == 0 {
+		return
+	}
+	for _, other := range others {
+		if compareExplanations(other, explanation) == 0 {
+			return
+		}
+	}
+	e.otherExplanations[site] = append(others, explanation)
+}
+
 // storeDeterminedAndActivateControlledTriggers stores the determined value for a site in the
 // inference map and if the site has proper value, then all the triggers controlled by this site
 // are also activated and will be used to build the inference map.
@@ -460,12 +736,15 @@ func (e *Engine) observeImplication(
 
 	// Nonnil (false) consumer => Nonnil (false) producer. We do not care about "ok" here since
 	// the "ok" in the type assertion below implies this "ok == true".
+	// Note that the producer may still be determined to be nilable by the pending observations,
+	// so the implication is kept pending until the nilable values are fully propagated in solve.
 	consumer, _ := e.inferredMap.Load(consumerSite)
 	if v, ok := consumer.(*DeterminedVal); ok {
 		if !v.Bool.Val() {
-			e.observeSiteExplanation(producerSite, FalseBecauseDeepConstraint{
-				InternalAssertion: assertion,
-				DeeperExplanation: v.Bool,
+			e.pendingNonnilImplications = append(e.pendingNonnilImplications, implicationObservation{
+				producer:  producerSite,
+				consumer:  consumerSite,
+				assertion: assertion,
 			})
 		}
 		return
@@ -480,7 +759,8 @@ func (e *Engine) observeImplication(
 // deal with InferredAnnotationMaps as Facts. If not, gob encoding/decoding will be unable to handle
 // the data structures.
 // The called function RegisterName maintains an internal mapping to ensure that the
-// association between names and structs is bijective
+// association between names and structs is bijective. Any change here changes the encoding of the
+// facts, so FactSchemaVersion must be bumped accordingly.
 func GobRegister() {
 	var curr rune
 	nextStr := func() string {
@@ -570,4 +850,13 @@ func GobRegister() {
 	gob.RegisterName(nextStr(), annotation.RecvPassPrestring{})
 	gob.RegisterName(nextStr(), annotation.MethodRecvDeepPrestring{})
 	gob.RegisterName(nextStr(), annotation.FldReturnPrestring{})
+	gob.RegisterName(nextStr(), annotation.FuncValueCallPrestring{})
+	gob.RegisterName(nextStr(), annotation.UnhandledExprPrestring{})
+	gob.RegisterName(nextStr(), annotation.TypedNilInInterfacePrestring{})
+	gob.RegisterName(nextStr(), FalseBecauseConstructorInit{})
+	gob.RegisterName(nextStr(), annotation.GlobalVarReadBeforeInitPrestring{})
+	gob.RegisterName(nextStr(), annotation.CallbackArgPassPrestring{})
+	gob.RegisterName(nextStr(), annotation.CallbackReturnPrestring{})
+	gob.RegisterName(nextStr(), annotation.CallbackParamPrestring{})
+	gob.RegisterName(nextStr(), annotation.CallbackResultPrestring{})
 }
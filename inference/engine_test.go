@@ -0,0 +1,210 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inference
+
+import (
+	"fmt"
+	"go/token"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion"
+	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/util/analysishelper"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// _orderAnalyzer runs the inference on the triggers of a package in several orders, and returns
+// the conflicts and the inferred map of each run (see describeRun).
+var _orderAnalyzer = &analysis.Analyzer{
+	Name:       "nilaway_inference_order_test",
+	Doc:        "Run the inference on the triggers of a package in several orders",
+	Run:        runInOrders,
+	Requires:   []*analysis.Analyzer{config.Analyzer, assertion.Analyzer, annotation.Analyzer},
+	ResultType: reflect.TypeOf((map[string]string)(nil)),
+}
+
+func runInOrders(pass *analysis.Pass) (any, error) {
+	assertionsResult := pass.ResultOf[assertion.Analyzer].(*analysishelper.Result[[]annotation.FullTrigger])
+	annotationsResult := pass.ResultOf[annotation.Analyzer].(*analysishelper.Result[*annotation.ObservedMap])
+	if assertionsResult.Err != nil {
+		return nil, assertionsResult.Err
+	}
+	if annotationsResult.Err != nil {
+		return nil, annotationsResult.Err
+	}
+
+	triggers := assertionsResult.Res
+	orders := map[string][]annotation.FullTrigger{
+		"original": triggers,
+		"reversed": slices.Clone(triggers),
+		// Observing the same triggers again should not yield more conflicts.
+		"doubled": append(slices.Clone(triggers), triggers...),
+	}
+	slices.Reverse(orders["reversed"])
+	for seed := int64(0); seed < 5; seed++ {
+		shuffled := slices.Clone(triggers)
+		rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		orders[fmt.Sprintf("shuffled %d", seed)] = shuffled
+	}
+
+	runs := make(map[string]string, len(orders))
+	for name, ordered := range orders {
+		recorder := &conflictRecorder{}
+		engine := NewEngine(pass, recorder)
+		engine.ObserveAnnotations(annotationsResult.Res, FullInfer)
+		engine.ObservePackage(ordered)
+		runs[name] = describeRun(recorder, engine.InferredMap())
+	}
+	return runs, nil
+}
+
+// conflictRecorder records the conflicts reported by the inference engine.
+type conflictRecorder struct {
+	single, overconstrained []string
+}
+
+func (r *conflictRecorder) AddSingleAssertionConflict(trigger annotation.FullTrigger) {
+	r.single = append(r.single, fmt.Sprintf("%s -> %s",
+		trigger.Producer.Annotation.Prestring(), trigger.Consumer.Annotation.Prestring()))
+}
+
+func (r *conflictRecorder) AddOverconstraintConflict(nilExplanation, nonnilExplanation ExplainedBool) {
+	r.overconstrained = append(r.overconstrained, fmt.Sprintf("%s: %s\n%s: %s",
+		nilExplanation.Position(), nilExplanation, nonnilExplanation.Position(), nonnilExplanation))
+}
+
+// describeRun describes the conflicts and the inferred map of a run. The single assertion
+// conflicts are sorted since they are reported as the triggers are observed, while the
+// overconstraint conflicts are kept in their (canonical) order. The sites of the inferred map and
+// the edges of the undetermined sites are sorted as well since they are stored in the order of
+// observation.
+func describeRun(recorder *conflictRecorder, inferredMap *InferredMap) string {
+	var b strings.Builder
+	slices.Sort(recorder.single)
+	for _, c := range recorder.single {
+		fmt.Fprintf(&b, "single assertion conflict: %s\n", c)
+	}
+	for _, c := range recorder.overconstrained {
+		fmt.Fprintf(&b, "overconstraint conflict: %s\n", c)
+	}
+
+	var sites []string
+	inferredMap.OrderedRange(func(site primitiveSite, val InferredVal) bool {
+		switch v := val.(type) {
+		case *DeterminedVal:
+			sites = append(sites, fmt.Sprintf("%s (deep: %t): %s", site.Repr, site.IsDeep, v.Bool))
+		case *UndeterminedVal:
+			var edges []string
+			for _, p := range v.Implicants.Pairs {
+				edges = append(edges, fmt.Sprintf("<- %s (deep: %t)", p.Key.Repr, p.Key.IsDeep))
+			}
+			for _, p := range v.Implicates.Pairs {
+				edges = append(edges, fmt.Sprintf("-> %s (deep: %t)", p.Key.Repr, p.Key.IsDeep))
+			}
+			slices.Sort(edges)
+			sites = append(sites, fmt.Sprintf("%s (deep: %t): undetermined %s", site.Repr, site.IsDeep, strings.Join(edges, ", ")))
+		}
+		return true
+	})
+	slices.Sort(sites)
+	for _, s := range sites {
+		fmt.Fprintln(&b, s)
+	}
+	return b.String()
+}
+
+func TestEngine_OrderIndependent(t *testing.T) {
+	t.Parallel()
+
+	r := analysistest.Run(t, analysistest.TestData(), _orderAnalyzer, "go.uber.org/order")
+	require.Len(t, r, 1)
+	require.NoError(t, r[0].Err)
+	runs := r[0].Result.(map[string]string)
+
+	expected := runs["original"]
+	require.Contains(t, expected, "overconstraint conflict")
+	for name, run := range runs {
+		require.Equal(t, expected, run, "different inference results when observing the triggers in the %s order", name)
+	}
+}
+
+// reprString is a fixed Prestring for the explanations constructed in the tests.
+type reprString string
+
+func (s reprString) String() string { return string(s) }
+
+func TestEngine_OverconstraintConflicts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// flows is the number of the distinct nil flows reaching the site, each observed twice.
+		flows int
+		// conflicts is the expected number of the overconstraint conflicts once the site is
+		// dereferenced, i.e., one for the determined explanation and one for each of the other
+		// explanations kept.
+		conflicts int
+	}{
+		{name: "deduplicated", flows: _maxOtherExplanations / 2, conflicts: _maxOtherExplanations / 2},
+		{name: "bounded", flows: 3 * _maxOtherExplanations, conflicts: 1 + _maxOtherExplanations},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := &conflictRecorder{}
+			e := &Engine{
+				inferredMap:       newInferredMap(nil /* primitive */),
+				diagnosticEngine:  recorder,
+				otherExplanations: make(map[primitiveSite][]ExplainedBool),
+			}
+			site := primitiveSite{PkgPath: "go.uber.org/overconstrained", Repr: "Result 0 of Function helper"}
+
+			// The flows are observed in separate rounds, such that the identical observations are
+			// not compacted in the same level by solve.
+			for round := 0; round < 2; round++ {
+				for i := 0; i < tt.flows; i++ {
+					e.observeSiteExplanation(site, TrueBecauseShallowConstraint{ExternalAssertion: primitiveFullTrigger{
+						Position:     token.Position{Filename: "helper.go", Line: i + 1, Column: 1},
+						ProducerRepr: reprString(fmt.Sprintf("literal `nil` %d", i)),
+						ConsumerRepr: reprString("returned from `helper()`"),
+					}})
+					e.solve()
+				}
+			}
+
+			e.observeSiteExplanation(site, FalseBecauseShallowConstraint{ExternalAssertion: primitiveFullTrigger{
+				Position:     token.Position{Filename: "helper.go", Line: tt.flows + 1, Column: 1},
+				ProducerRepr: reprString("result 0 of `helper()`"),
+				ConsumerRepr: reprString("dereferenced"),
+			}})
+			e.solve()
+			require.Len(t, recorder.overconstrained, tt.conflicts)
+			distinct := slices.Clone(recorder.overconstrained)
+			slices.Sort(distinct)
+			require.Len(t, slices.Compact(distinct), tt.conflicts, "duplicate overconstraint conflicts")
+		})
+	}
+}
@@ -235,3 +235,31 @@ func (FalseBecauseAnnotation) TriggerReprs() (fmt.Stringer, fmt.Stringer) {
 func (f FalseBecauseAnnotation) DeeperReason() ExplainedBool {
 	return nil
 }
+
+// FalseBecauseConstructorInit is used as the label for a field site X that is not annotated, but
+// is always set by the constructors of its struct type and never reassigned to nil - forcing that
+// site to be nonnil.
+type FalseBecauseConstructorInit struct {
+	ExplainedFalse
+	AnnotationPos token.Position
+}
+
+func (FalseBecauseConstructorInit) String() string {
+	return "NONNIL because it is always set by the constructors of its struct type"
+}
+
+// Position is the position of underlying site.
+func (f FalseBecauseConstructorInit) Position() token.Position {
+	return f.AnnotationPos
+}
+
+// TriggerReprs simply returns nil, nil since this constraint is the result of the constructors.
+func (FalseBecauseConstructorInit) TriggerReprs() (fmt.Stringer, fmt.Stringer) {
+	return nil, nil
+}
+
+// DeeperReason returns another ExplainedBool that marks the deeper reason of this constraint.
+// It is only nonnil for deep constraints.
+func (FalseBecauseConstructorInit) DeeperReason() ExplainedBool {
+	return nil
+}
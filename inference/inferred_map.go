@@ -16,6 +16,7 @@ package inference
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/gob"
 	"errors"
 	"go/types"
@@ -44,14 +45,29 @@ type InferredMap struct {
 	primitive       *primitivizer
 	upstreamMapping map[primitiveSite]InferredVal
 	mapping         *orderedmap.OrderedMap[primitiveSite, InferredVal]
+	// schemaVersion is the FactSchemaVersion of the map. It is always the current version for
+	// maps created in this run, and it is the version read from the encoded bytes for the maps
+	// decoded from upstream facts (0 if the facts do not carry a version at all).
+	schemaVersion uint64
 }
 
+// FactSchemaVersion is the version of the encoding of InferredMap facts. It must be bumped
+// whenever the encoding changes, e.g., when the encoded structs change or when new types are
+// registered in GobRegister. Facts with a different version (e.g., stale facts in a build cache
+// produced by a different NilAway version) are never decoded, see InferredMap.GobDecode.
+const FactSchemaVersion uint64 = 1
+
+// _factMagic is the header of the encoded InferredMap facts, followed by the schema version
+// (as an uvarint) and then the s2-compressed gob encoding of the map.
+const _factMagic = "nilaway-fact"
+
 // newInferredMap returns a new, empty InferredMap.
 func newInferredMap(primitive *primitivizer) *InferredMap {
 	return &InferredMap{
 		primitive:       primitive,
 		upstreamMapping: make(map[primitiveSite]InferredVal),
 		mapping:         orderedmap.New[primitiveSite, InferredVal](),
+		schemaVersion:   FactSchemaVersion,
 	}
 }
 
@@ -101,6 +117,27 @@ func (i *InferredMap) OrderedRange(f func(primitiveSite, InferredVal) bool) {
 	}
 }
 
+// CountSites returns the numbers of the sites of the given package in the map that are determined
+// nilable, determined nonnil, and undetermined, respectively.
+func (i *InferredMap) CountSites(pkgPath string) (nilable, nonnil, undetermined int) {
+	for _, p := range i.mapping.Pairs {
+		if p.Key.PkgPath != pkgPath {
+			continue
+		}
+		switch v := p.Value.(type) {
+		case *DeterminedVal:
+			if v.Bool.Val() {
+				nilable++
+			} else {
+				nonnil++
+			}
+		case *UndeterminedVal:
+			undetermined++
+		}
+	}
+	return nilable, nonnil, undetermined
+}
+
 // Export only encodes new information not already present in the upstream maps, and it does not
 // encode all (in the go sense; i.e. capitalized) annotation sites (See chooseSitesToExport).
 // This ensures that only _incremental_ information is exported by this package and plays a _vital_
@@ -162,9 +199,11 @@ func (i *InferredMap) Export(pass *analysis.Pass) {
 	}
 }
 
-// GobEncode encodes the inferred map via gob encoding.
+// GobEncode encodes the inferred map via gob encoding, prefixed by the schema version.
 func (i *InferredMap) GobEncode() (b []byte, err error) {
 	var buf bytes.Buffer
+	buf.WriteString(_factMagic)
+	buf.Write(binary.AppendUvarint(nil, FactSchemaVersion))
 	writer := s2.NewWriter(&buf)
 	defer func() {
 		if cerr := writer.Close(); cerr != nil {
@@ -183,12 +222,29 @@ func (i *InferredMap) GobEncode() (b []byte, err error) {
 	return buf.Bytes(), nil
 }
 
-// GobDecode decodes the InferredMap from buffer.
+// GobDecode decodes the InferredMap from buffer. If the encoded map has a different (or no)
+// schema version, GobDecode leaves the map empty without an error since the rest of the input
+// cannot be reliably decoded. It is up to the callers to check the schema version of the decoded
+// map (see Engine.ObserveUpstream).
 func (i *InferredMap) GobDecode(input []byte) error {
 	i.mapping = orderedmap.New[primitiveSite, InferredVal]()
 	i.upstreamMapping = make(map[primitiveSite]InferredVal)
 
-	buf := bytes.NewBuffer(input)
+	i.schemaVersion = 0
+	rest, ok := bytes.CutPrefix(input, []byte(_factMagic))
+	if !ok {
+		return nil
+	}
+	version, n := binary.Uvarint(rest)
+	if n <= 0 {
+		return nil
+	}
+	i.schemaVersion = version
+	if version != FactSchemaVersion {
+		return nil
+	}
+
+	buf := bytes.NewBuffer(rest[n:])
 	return gob.NewDecoder(s2.NewReader(buf)).Decode(&i.mapping)
 }
 
@@ -301,6 +357,26 @@ func (i *InferredMap) CheckFuncCallSiteRetAnn(key *annotation.CallSiteRetAnnotat
 	return i.checkAnnotationKey(key)
 }
 
+// CheckCallbackAnn checks this InferredMap for a concrete mapping of the callback key provided.
+func (i *InferredMap) CheckCallbackAnn(key *annotation.CallbackAnnotationKey) (annotation.Val, bool) {
+	return i.checkAnnotationKey(key)
+}
+
+// CheckShallowAnn checks this InferredMap for the determined (shallow) nilability of the key
+// provided, regardless of its deep nilability. The second return value is false if the
+// nilability has not been determined.
+func (i *InferredMap) CheckShallowAnn(key annotation.Key) (isNilable bool, ok bool) {
+	val, ok := i.mapping.Load(i.primitive.site(key, false))
+	if !ok {
+		return false, false
+	}
+	determined, ok := val.(*DeterminedVal)
+	if !ok {
+		return false, false
+	}
+	return determined.Bool.Val(), true
+}
+
 func (i *InferredMap) checkAnnotationKey(key annotation.Key) (annotation.Val, bool) {
 	shallowKey := i.primitive.site(key, false)
 	deepKey := i.primitive.site(key, true)
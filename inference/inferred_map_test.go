@@ -104,6 +104,34 @@ func TestDecoding(t *testing.T) {
 	require.Equal(t, value, v.(*DeterminedVal).Bool)
 }
 
+func TestDecoding_SchemaVersion(t *testing.T) {
+	t.Parallel()
+
+	m := newBigInferredMap()
+	encoded, err := m.GobEncode()
+	require.NoError(t, err)
+
+	// The current schema version should be decoded as is.
+	var decodedMap InferredMap
+	require.NoError(t, decodedMap.GobDecode(encoded))
+	require.Equal(t, FactSchemaVersion, decodedMap.schemaVersion)
+	require.Equal(t, m.Len(), decodedMap.Len())
+
+	// Facts from a different schema version should be left empty, without decoding errors.
+	mismatched := bytes.Clone(encoded)
+	mismatched[len(_factMagic)]++
+	require.NoError(t, decodedMap.GobDecode(mismatched))
+	require.Equal(t, FactSchemaVersion+1, decodedMap.schemaVersion)
+	require.Zero(t, decodedMap.Len())
+
+	// Facts without any schema version (e.g., produced before the versioning was introduced)
+	// should be treated the same.
+	unversioned := encoded[len(_factMagic)+1:]
+	require.NoError(t, decodedMap.GobDecode(unversioned))
+	require.Zero(t, decodedMap.schemaVersion)
+	require.Zero(t, decodedMap.Len())
+}
+
 // newBigInferredMap creates an inferred map with 3000 sites, where the first 1000 are determined,
 // and the next 2000 with implications between them for stress testing.
 func newBigInferredMap() *InferredMap {
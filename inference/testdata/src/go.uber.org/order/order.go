@@ -0,0 +1,45 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package order tests that the inference results do not depend on the order of the observed
+// triggers. The parameter of `sink` is reached by several nil flows, and is overconstrained since
+// it is also dereferenced.
+package order
+
+var global *int
+
+func source() *int { return nil }
+
+func id(p *int) *int { return p }
+
+func sink(p *int) int { return *p }
+
+func literal() { sink(nil) }
+
+func call() { sink(source()) }
+
+func wrapped() { sink(id(nil)) }
+
+func both(ok bool) *int {
+	if ok {
+		return id(nil)
+	}
+	return source()
+}
+
+func deref() {
+	print(*both(true))
+	print(*id(global))
+	sink(both(false))
+}
@@ -17,6 +17,8 @@
 package nilaway
 
 import (
+	"reflect"
+
 	"go.uber.org/nilaway/accumulation"
 	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/util"
@@ -29,22 +31,46 @@ const _doc = "Run NilAway on this package to report any possible flows of nil va
 // Analyzer is the top-level instance of Analyzer - it coordinates the entire dataflow to report
 // nil flow errors in this package. It is needed here for nogo to recognize the package.
 var Analyzer = &analysis.Analyzer{
-	Name:      "nilaway",
-	Doc:       _doc,
-	Run:       run,
-	FactTypes: []analysis.Fact{},
-	Requires:  []*analysis.Analyzer{config.Analyzer, accumulation.Analyzer},
+	Name:       "nilaway",
+	Doc:        _doc,
+	Run:        run,
+	FactTypes:  []analysis.Fact{},
+	ResultType: reflect.TypeOf((*Result)(nil)),
+	Requires:   []*analysis.Analyzer{config.Analyzer, accumulation.Analyzer},
+}
+
+// Result is the result of the top-level analyzer.
+type Result struct {
+	// Warnings are the diagnostics of the downgraded categories (see
+	// config.DowngradeCategoriesFlag). They are not reported via the analysis framework, since
+	// the drivers (e.g., nogo and golangci-lint) treat every reported diagnostic as an error.
+	// Instead, they are only available to the drivers that understand them (e.g., the standalone
+	// checker, which prints them without failing).
+	Warnings []analysis.Diagnostic
 }
 
 func run(pass *analysis.Pass) (interface{}, error) {
 	conf := pass.ResultOf[config.Analyzer].(*config.Config)
-	deferredErrors := pass.ResultOf[accumulation.Analyzer].([]analysis.Diagnostic)
+	res := pass.ResultOf[accumulation.Analyzer].(*accumulation.Result)
+	deferredErrors := res.Diagnostics
+	if conf.Annotate {
+		// Suggest the inferred annotations instead of reporting the potential nil panics.
+		deferredErrors = res.Annotations
+	}
+	result := &Result{}
 	for _, e := range deferredErrors {
+		if !conf.Annotate && conf.IsCategoryDowngraded(e.Category, pass.Pkg) {
+			if conf.PrettyPrint {
+				e.Message = util.PrettyPrintWarningMessage(e.Message)
+			}
+			result.Warnings = append(result.Warnings, e)
+			continue
+		}
 		if conf.PrettyPrint {
 			e.Message = util.PrettyPrintErrorMessage(e.Message)
 		}
 		pass.Report(e)
 	}
 
-	return nil, nil
+	return result, nil
 }
@@ -67,6 +67,12 @@ func TestNilAway(t *testing.T) {
 		{name: "ErrorMessage", patterns: []string{"go.uber.org/errormessage", "go.uber.org/errormessage/inference"}},
 		{name: "LoopRange", patterns: []string{"go.uber.org/looprange"}},
 		{name: "AbnormalFlow", patterns: []string{"go.uber.org/abnormalflow"}},
+		{name: "Strict", patterns: []string{"go.uber.org/strict"}},
+		{name: "NonNilResults", patterns: []string{"go.uber.org/nonnilresults"}},
+		{name: "TypedNil", patterns: []string{"go.uber.org/typednil"}},
+		{name: "Constructor", patterns: []string{"go.uber.org/constructor"}},
+		{name: "Callbacks", patterns: []string{"go.uber.org/callbacks"}},
+		{name: "Concurrency", patterns: []string{"go.uber.org/concurrency"}},
 	}
 
 	for _, tt := range tests {
@@ -80,6 +86,21 @@ func TestNilAway(t *testing.T) {
 	}
 }
 
+func TestCategories(t *testing.T) {
+	t.Parallel()
+
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, Analyzer, "go.uber.org/categories")
+
+	// The diagnostics of the downgraded categories are not reported, but returned as warnings.
+	require.Len(t, results, 1)
+	warnings := results[0].Result.(*Result).Warnings
+	require.Len(t, warnings, 1)
+	require.Equal(t, "field-access", warnings[0].Category)
+	require.Contains(t, warnings[0].Message, "(downgraded to warning)")
+	require.Equal(t, 35, results[0].Pass.Fset.Position(warnings[0].Pos).Line)
+}
+
 func TestStructInit(t *testing.T) { //nolint:paralleltest
 	// We specifically do not set this test to be parallel since we need to enable the
 	// experimental support for struct initialization to test this feature.
@@ -105,7 +126,25 @@ func TestAnonymousFunction(t *testing.T) { //nolint:paralleltest
 	}()
 
 	testdata := analysistest.TestData()
-	analysistest.Run(t, testdata, Analyzer, "go.uber.org/anonymousfunction")
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/anonymousfunction", "go.uber.org/anonymousfunction/annotations")
+}
+
+func TestAnnotate(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to enable the annotate
+	// mode to test this feature.
+	for _, f := range [...]string{config.AnnotateFlag, config.AnnotateNonnilFlag} {
+		err := config.Analyzer.Flags.Set(f, "true")
+		require.NoError(t, err)
+	}
+	defer func() {
+		for _, f := range [...]string{config.AnnotateFlag, config.AnnotateNonnilFlag} {
+			err := config.Analyzer.Flags.Set(f, "false")
+			require.NoError(t, err)
+		}
+	}()
+
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "go.uber.org/annotate")
 }
 
 func TestPrettyPrint(t *testing.T) { //nolint:paralleltest
@@ -152,6 +191,13 @@ func TestMain(m *testing.M) {
 		config.PrettyPrintFlag:           "false",
 		config.ExcludeFileDocStringsFlag: "@generated,Code generated by",
 		config.ExcludePkgsFlag:           "ignoredpkg1,ignoredpkg2",
+		// Strict mode is enabled only for the dedicated test package.
+		config.StrictPkgsFlag: "go.uber.org/strict",
+		// The stub file annotates a function in the dedicated test package.
+		config.StubFilesFlag: "testdata/src/go.uber.org/nonnilresults/stubs.txt",
+		// The diagnostic categories are configured only for the dedicated test package.
+		config.DisableCategoriesFlag:   "deep-read:go.uber.org/categories",
+		config.DowngradeCategoriesFlag: "field-access:go.uber.org/categories",
 	}
 	for f, v := range flags {
 		if err := config.Analyzer.Flags.Set(f, v); err != nil {
@@ -0,0 +1,532 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package repro implements the automatic minimization of reproducers for NilAway diagnostics
+// (e.g., false positives found in large code bases): it delta-debugs the source of a package,
+// removing files, declarations, statements, and imports while the target diagnostic still
+// reproduces, and emits a minimal package in the style of NilAway's test data.
+package repro
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.uber.org/nilaway/driver"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Options configures Minimize.
+type Options struct {
+	// Dir is the directory in which to load the package, empty means the current directory.
+	Dir string
+	// Env is the environment for loading the package, nil means the current environment.
+	Env []string
+}
+
+// Result is a minimized reproducer.
+type Result struct {
+	// PkgName is the name of the package.
+	PkgName string
+	// Files maps the base names of the remaining files to their minimized contents, where the line
+	// of the target diagnostic is marked with a `// want` comment for the analysistest framework.
+	Files map[string][]byte
+	// Imports are the remaining imports that are not in the standard library, which must be made
+	// available in (or removed from) the test data for the reproducer to be self-contained.
+	Imports []string
+	// Runs is the number of NilAway runs during the minimization.
+	Runs int
+}
+
+// Write writes the files of the reproducer to the directory, e.g., "testdata/src/go.uber.org/foo".
+func (r *Result) Write(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create directory %q: %w", dir, err)
+	}
+	for name, content := range r.Files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return fmt.Errorf("write file %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// target identifies the target diagnostic in a way that is stable under the minimization (i.e.,
+// independent of the positions).
+type target struct {
+	// file is the absolute path of the file where the diagnostic is reported.
+	file string
+	// category is the category of the diagnostic.
+	category string
+	// line is the trimmed source line where the diagnostic is reported.
+	line string
+}
+
+// minimizer holds the states of a minimization.
+type minimizer struct {
+	ctx     context.Context
+	program *driver.Program
+	target  target
+	runs    int
+}
+
+// Minimize minimizes the source of the package matching the pattern while the NilAway diagnostic
+// reported at the target position still reproduces, and returns the minimized reproducer. The
+// target position must have an absolute file name and a line number, and optionally a column
+// number to choose among the diagnostics on the same line. Note that NilAway is configured by the
+// flags of config.Analyzer, and the dependencies of the package are analyzed only once.
+func Minimize(ctx context.Context, pattern string, position token.Position, opts Options) (*Result, error) {
+	program, err := driver.Load(ctx, pattern, driver.Options{Dir: opts.Dir, Env: opts.Env})
+	if err != nil {
+		return nil, err
+	}
+	pkg := program.Root()
+	if !slices.Contains(pkg.GoFiles, position.Filename) {
+		return nil, fmt.Errorf("file %q is not in package %q", position.Filename, pkg.PkgPath)
+	}
+
+	srcs := make(map[string][]byte, len(pkg.GoFiles))
+	for _, f := range pkg.GoFiles {
+		if srcs[f], err = os.ReadFile(f); err != nil {
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+	}
+
+	m := &minimizer{ctx: ctx, program: program}
+	if err := m.findTarget(srcs, position); err != nil {
+		return nil, err
+	}
+
+	// Reduce the source at decreasing granularities until no more reduction is possible.
+	levels := []func(map[string][]byte) ([]unit, error){m.fileUnits, declUnits, stmtUnits, importUnits}
+	for changed := true; changed; {
+		changed = false
+		for _, level := range levels {
+			units, err := level(srcs)
+			if err != nil {
+				return nil, err
+			}
+			// Removing blank units does not make any progress.
+			units = slices.DeleteFunc(units, func(u unit) bool {
+				return len(bytes.TrimSpace(srcs[u.file][u.start:u.end])) == 0
+			})
+			var reproErr error
+			keep := ddmin(len(units), func(keep []int) bool {
+				if reproErr != nil {
+					return false
+				}
+				ok, err := m.reproduces(removeUnits(srcs, units, keep))
+				reproErr = err
+				return ok
+			})
+			if reproErr != nil {
+				return nil, reproErr
+			}
+			if len(keep) < len(units) {
+				srcs = removeUnits(srcs, units, keep)
+				changed = true
+			}
+		}
+	}
+
+	if srcs, err = m.fixSoftErrors(srcs); err != nil {
+		return nil, err
+	}
+	return m.result(srcs)
+}
+
+// findTarget finds the diagnostic at the position and records it as the target.
+func (m *minimizer) findTarget(srcs map[string][]byte, position token.Position) error {
+	m.runs++
+	res, err := m.program.Analyze(srcs)
+	if err != nil {
+		return err
+	}
+	for _, d := range res.Diagnostics {
+		p := res.Fset.Position(d.Pos)
+		if p.Filename != position.Filename || p.Line != position.Line || (position.Column != 0 && p.Column != position.Column) {
+			continue
+		}
+		m.target = target{file: p.Filename, category: d.Category, line: lineAt(srcs[p.Filename], p.Line)}
+		return nil
+	}
+	return fmt.Errorf("no diagnostic reported at %s", position)
+}
+
+// reproduces returns true iff the target diagnostic is still reported on the sources. The sources
+// that fail to parse or type check simply do not reproduce (except for the soft type errors, which
+// are fixed at the end, see fixSoftErrors), and only the errors from the context (e.g.,
+// cancellation) are returned.
+func (m *minimizer) reproduces(srcs map[string][]byte) (bool, error) {
+	if err := m.ctx.Err(); err != nil {
+		return false, err
+	}
+	m.runs++
+	res, err := m.program.Analyze(srcs)
+	if err != nil {
+		return false, nil
+	}
+	for _, d := range res.Diagnostics {
+		p := res.Fset.Position(d.Pos)
+		if p.Filename == m.target.file && d.Category == m.target.category && lineAt(srcs[p.Filename], p.Line) == m.target.line {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fixSoftErrors fixes the soft type errors (i.e., unused variables and imports) that are tolerated
+// during the minimization, such that the reproducer compiles: the unused imports are removed, and
+// the unused variables are used by blank assignments right after their declarations.
+func (m *minimizer) fixSoftErrors(srcs map[string][]byte) (map[string][]byte, error) {
+	// Each round fixes all soft errors reported, and the fixes do not introduce new ones, so at most
+	// a few rounds are needed (the type checker may stop reporting after the first few errors).
+	for round := 0; round < _maxFixRounds; round++ {
+		m.runs++
+		res, err := m.program.Analyze(srcs)
+		if err != nil {
+			return nil, err
+		}
+		if len(res.SoftErrors) == 0 {
+			if ok, err := m.reproduces(srcs); err != nil || !ok {
+				return nil, errors.Join(err, errors.New("the diagnostic does not reproduce after fixing the soft type errors"))
+			}
+			return srcs, nil
+		}
+
+		offsets := make(map[string][]int)
+		for _, e := range res.SoftErrors {
+			p := e.Fset.Position(e.Pos)
+			offsets[p.Filename] = append(offsets[p.Filename], p.Offset)
+		}
+		fixed := make(map[string][]byte, len(srcs))
+		for name, src := range srcs {
+			if fixed[name], err = fixFile(name, src, offsets[name]); err != nil {
+				return nil, err
+			}
+		}
+		srcs = fixed
+	}
+	return nil, fmt.Errorf("cannot fix the soft type errors in %d rounds", _maxFixRounds)
+}
+
+// _maxFixRounds is the maximum number of rounds for fixing the soft type errors.
+const _maxFixRounds = 5
+
+// fixFile fixes the soft type errors at the offsets in the source, see fixSoftErrors.
+func fixFile(name string, src []byte, offsets []int) ([]byte, error) {
+	if len(offsets) == 0 {
+		return src, nil
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("parse file %q: %w", name, err)
+	}
+	tf := fset.File(file.Pos())
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	for _, offset := range offsets {
+		pos := tf.Pos(offset)
+		path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+		for i, n := range path {
+			if spec, ok := n.(*ast.ImportSpec); ok {
+				// A single unparenthesized import must be removed along with its declaration.
+				var node ast.Node = spec
+				if decl, ok := path[i+1].(*ast.GenDecl); ok && !decl.Lparen.IsValid() {
+					node = decl
+				}
+				u := offsetRange(name, fset, node, nil)
+				edits = append(edits, edit{start: u.start, end: u.end})
+				break
+			}
+			// Otherwise, find the statement declaring the unused variable in a statement list.
+			stmt, ok := n.(ast.Stmt)
+			if !ok || i+1 >= len(path) {
+				continue
+			}
+			switch path[i+1].(type) {
+			case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+			default:
+				continue
+			}
+			if ident, ok := path[0].(*ast.Ident); ok {
+				end := fset.Position(stmt.End()).Offset
+				edits = append(edits, edit{start: end, end: end, text: "\n_ = " + ident.Name})
+			}
+			break
+		}
+	}
+
+	// Apply the edits backwards so that the offsets of the remaining ones stay valid.
+	slices.SortFunc(edits, func(a, b edit) int { return b.start - a.start })
+	out := slices.Clone(src)
+	for _, e := range edits {
+		out = append(append(out[:e.start:e.start], e.text...), out[e.end:]...)
+	}
+	return out, nil
+}
+
+// result formats the minimized sources and builds the reproducer.
+func (m *minimizer) result(srcs map[string][]byte) (*Result, error) {
+	r := &Result{PkgName: m.program.Root().Name, Files: make(map[string][]byte), Runs: m.runs}
+	fset := token.NewFileSet()
+	for name, src := range srcs {
+		if formatted, err := format.Source(src); err == nil {
+			src = formatted
+		}
+		file, err := parser.ParseFile(fset, name, src, parser.ImportsOnly)
+		if err != nil {
+			return nil, fmt.Errorf("parse minimized file %q: %w", name, err)
+		}
+		for _, imp := range file.Imports {
+			path := strings.Trim(imp.Path.Value, `"`)
+			// The import paths in the standard library do not have dots in the first elements.
+			if strings.Contains(strings.Split(path, "/")[0], ".") && !slices.Contains(r.Imports, path) {
+				r.Imports = append(r.Imports, path)
+			}
+		}
+
+		if name == m.target.file {
+			src = markTarget(src, m.target)
+		} else if isEmpty(src) {
+			continue
+		}
+		r.Files[filepath.Base(name)] = src
+	}
+	slices.Sort(r.Imports)
+	return r, nil
+}
+
+// markTarget appends the `// want` comment to the (first) target line in the source.
+func markTarget(src []byte, t target) []byte {
+	lines := strings.Split(string(src), "\n")
+	for i, l := range lines {
+		if strings.TrimSpace(l) == t.line {
+			lines[i] = l + fmt.Sprintf(" // want %q", t.category)
+			break
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// isEmpty returns true iff the file has no declarations.
+func isEmpty(src []byte) bool {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	return err == nil && len(file.Decls) == 0
+}
+
+// lineAt returns the trimmed line at the (1-based) line number in the source.
+func lineAt(src []byte, line int) string {
+	lines := strings.Split(string(src), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
+
+// unit is a unit of the source that can be removed, i.e., a range of bytes in a file.
+type unit struct {
+	file       string
+	start, end int
+}
+
+// removeUnits returns the sources with all units except the kept ones (given by their indices)
+// removed. The units can overlap with each other.
+func removeUnits(srcs map[string][]byte, units []unit, keep []int) map[string][]byte {
+	kept := make(map[int]bool, len(keep))
+	for _, i := range keep {
+		kept[i] = true
+	}
+	ranges := make(map[string][][2]int)
+	for i, u := range units {
+		if !kept[i] {
+			ranges[u.file] = append(ranges[u.file], [2]int{u.start, u.end})
+		}
+	}
+
+	result := make(map[string][]byte, len(srcs))
+	for name, src := range srcs {
+		rs := ranges[name]
+		slices.SortFunc(rs, func(a, b [2]int) int { return a[0] - b[0] })
+		var buf []byte
+		last := 0
+		for _, r := range rs {
+			if r[0] > last {
+				buf = append(buf, src[last:r[0]]...)
+			}
+			last = max(last, r[1])
+		}
+		result[name] = append(buf, src[last:]...)
+	}
+	return result
+}
+
+// parseAll parses all sources, and calls f for each file.
+func parseAll(srcs map[string][]byte, f func(name string, fset *token.FileSet, file *ast.File)) error {
+	names := make([]string, 0, len(srcs))
+	for name := range srcs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, name, srcs[name], parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("parse file %q: %w", name, err)
+		}
+		f(name, fset, file)
+	}
+	return nil
+}
+
+// offsetRange returns the unit for the node (along with its doc comment, if any).
+func offsetRange(name string, fset *token.FileSet, node ast.Node, doc *ast.CommentGroup) unit {
+	start := node.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	return unit{file: name, start: fset.Position(start).Offset, end: fset.Position(node.End()).Offset}
+}
+
+// fileUnits returns the contents (after the package clauses) of the files other than the target
+// file as the units.
+func (m *minimizer) fileUnits(srcs map[string][]byte) ([]unit, error) {
+	var units []unit
+	err := parseAll(srcs, func(name string, fset *token.FileSet, file *ast.File) {
+		if name != m.target.file {
+			units = append(units, unit{file: name, start: fset.Position(file.Name.End()).Offset, end: len(srcs[name])})
+		}
+	})
+	return units, err
+}
+
+// declUnits returns the top-level declarations (or the specs of the grouped declarations) other
+// than the imports as the units.
+func declUnits(srcs map[string][]byte) ([]unit, error) {
+	var units []unit
+	err := parseAll(srcs, func(name string, fset *token.FileSet, file *ast.File) {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				units = append(units, offsetRange(name, fset, decl, decl.Doc))
+			case *ast.GenDecl:
+				if decl.Tok == token.IMPORT {
+					continue
+				}
+				if !decl.Lparen.IsValid() {
+					units = append(units, offsetRange(name, fset, decl, decl.Doc))
+					continue
+				}
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						units = append(units, offsetRange(name, fset, spec, spec.Doc))
+					case *ast.TypeSpec:
+						units = append(units, offsetRange(name, fset, spec, spec.Doc))
+					}
+				}
+			}
+		}
+	})
+	return units, err
+}
+
+// stmtUnits returns the statements in all blocks (including the nested ones) as the units.
+func stmtUnits(srcs map[string][]byte) ([]unit, error) {
+	var units []unit
+	err := parseAll(srcs, func(name string, fset *token.FileSet, file *ast.File) {
+		ast.Inspect(file, func(n ast.Node) bool {
+			var list []ast.Stmt
+			switch n := n.(type) {
+			case *ast.BlockStmt:
+				list = n.List
+			case *ast.CaseClause:
+				list = n.Body
+			case *ast.CommClause:
+				list = n.Body
+			}
+			for _, stmt := range list {
+				units = append(units, offsetRange(name, fset, stmt, nil))
+			}
+			return true
+		})
+	})
+	return units, err
+}
+
+// importUnits returns the import specs (and the empty import declarations) as the units.
+func importUnits(srcs map[string][]byte) ([]unit, error) {
+	var units []unit
+	err := parseAll(srcs, func(name string, fset *token.FileSet, file *ast.File) {
+		for _, imp := range file.Imports {
+			units = append(units, offsetRange(name, fset, imp, imp.Doc))
+		}
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.IMPORT && len(decl.Specs) == 0 {
+				units = append(units, offsetRange(name, fset, decl, decl.Doc))
+			}
+		}
+	})
+	return units, err
+}
+
+// ddmin implements the delta debugging algorithm, which returns a 1-minimal subset (given by the
+// indices) of the n units such that the test still passes, i.e., removing any single unit from
+// the subset makes the test fail. The test must pass with all units.
+func ddmin(n int, test func(keep []int) bool) []int {
+	items := make([]int, n)
+	for i := range items {
+		items[i] = i
+	}
+	if n == 0 || test(nil) {
+		return nil
+	}
+
+	granularity := 2
+	for len(items) >= 2 {
+		chunkSize := (len(items) + granularity - 1) / granularity
+		reduced := false
+		for start := 0; start < len(items); start += chunkSize {
+			complement := append(slices.Clone(items[:start]), items[min(start+chunkSize, len(items)):]...)
+			if test(complement) {
+				items = complement
+				granularity = max(granularity-1, 2)
+				reduced = true
+				break
+			}
+		}
+		if reduced {
+			continue
+		}
+		if granularity >= len(items) {
+			break
+		}
+		granularity = min(granularity*2, len(items))
+	}
+	return items
+}
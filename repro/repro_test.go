@@ -0,0 +1,191 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repro
+
+import (
+	"context"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/nilaway/driver"
+)
+
+func TestMinimize(t *testing.T) {
+	t.Parallel()
+
+	gopath, err := filepath.Abs(filepath.Join("..", "testdata"))
+	require.NoError(t, err)
+	env := append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off", "GOPROXY=off")
+	file := filepath.Join(gopath, "src", "go.uber.org", "repro", "a.go")
+
+	res, err := Minimize(context.Background(), "go.uber.org/repro", token.Position{Filename: file, Line: 46}, Options{Env: env})
+	require.NoError(t, err)
+	require.Equal(t, "repro", res.PkgName)
+	require.Empty(t, res.Imports)
+	require.Positive(t, res.Runs)
+
+	// Only the nil source, the assignment, and the dereference should remain (along with the
+	// non-nil return in find and the license header), where the unused variables and imports
+	// that are tolerated during the minimization must not be left behind.
+	require.Len(t, res.Files, 1)
+	require.Contains(t, res.Files, "a.go")
+	require.Equal(t, `//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package repro contains a diagnostic among unrelated code to test the minimization of reproducers.
+package repro
+
+func find(k string) *int {
+
+	if k == "" {
+		return nil
+	}
+	v := len(k)
+	return &v
+}
+
+func use(k string) int {
+
+	p := find(k)
+
+	return *p // want "ptr-load"
+}
+`, string(res.Files["a.go"]))
+
+	dir := t.TempDir()
+	require.NoError(t, res.Write(dir))
+	content, err := os.ReadFile(filepath.Join(dir, "a.go"))
+	require.NoError(t, err)
+	require.Equal(t, res.Files["a.go"], content)
+}
+
+func TestMinimize_NoDiagnostic(t *testing.T) {
+	t.Parallel()
+
+	gopath, err := filepath.Abs(filepath.Join("..", "testdata"))
+	require.NoError(t, err)
+	env := append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off", "GOPROXY=off")
+	file := filepath.Join(gopath, "src", "go.uber.org", "repro", "a.go")
+
+	_, err = Minimize(context.Background(), "go.uber.org/repro", token.Position{Filename: file, Line: 45}, Options{Env: env})
+	require.ErrorContains(t, err, "no diagnostic")
+}
+
+func TestReproduces_HardErrorAfterSoftError(t *testing.T) {
+	t.Parallel()
+
+	gopath, err := filepath.Abs(filepath.Join("..", "testdata"))
+	require.NoError(t, err)
+	env := append(os.Environ(), "GOPATH="+gopath, "GO111MODULE=off", "GOPROXY=off")
+	file := filepath.Join(gopath, "src", "go.uber.org", "repro", "a.go")
+
+	program, err := driver.Load(context.Background(), "go.uber.org/repro", driver.Options{Env: env})
+	require.NoError(t, err)
+	srcs := make(map[string][]byte)
+	for _, f := range program.Root().GoFiles {
+		srcs[f], err = os.ReadFile(f)
+		require.NoError(t, err)
+	}
+	m := &minimizer{ctx: context.Background(), program: program}
+	require.NoError(t, m.findTarget(srcs, token.Position{Filename: file, Line: 46}))
+
+	// Removing describe leaves an undefined reference in use, while the diagnostic is still
+	// reported. The unused variable in find is a soft error reported before the hard one, and
+	// must not hide it.
+	src := string(srcs[file])
+	start, end := strings.Index(src, "func describe()"), strings.Index(src, "func find(")
+	src = src[:start] + src[end:]
+	src = strings.Replace(src, "\tcounter++\n", "\tcounter++\n\tunused := 0\n", 1)
+	candidate := map[string][]byte{file: []byte(src)}
+	for name, content := range srcs {
+		if name != file {
+			candidate[name] = content
+		}
+	}
+
+	_, err = program.Analyze(candidate)
+	require.ErrorIs(t, err, driver.ErrTypeCheck)
+	require.ErrorContains(t, err, "undefined: describe")
+	ok, err := m.reproduces(candidate)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestFixFile(t *testing.T) {
+	t.Parallel()
+
+	src := `package foo
+
+import "strings"
+
+func f() {
+	x, y := 1, 2
+	_ = y
+}
+`
+	// The offsets of the unused import and variable.
+	offsets := []int{strings.Index(src, `"strings"`), strings.Index(src, "x, y")}
+	fixed, err := fixFile("foo.go", []byte(src), offsets)
+	require.NoError(t, err)
+	require.Equal(t, `package foo
+
+
+
+func f() {
+	x, y := 1, 2
+_ = x
+	_ = y
+}
+`, string(fixed))
+}
+
+func TestDDMin(t *testing.T) {
+	t.Parallel()
+
+	// The test passes iff both 3 and 7 are kept.
+	keep := ddmin(10, func(keep []int) bool {
+		count := 0
+		for _, i := range keep {
+			if i == 3 || i == 7 {
+				count++
+			}
+		}
+		return count == 2
+	})
+	require.Equal(t, []int{3, 7}, keep)
+
+	require.Empty(t, ddmin(10, func([]int) bool { return true }))
+	require.Empty(t, ddmin(0, func([]int) bool { return false }))
+}
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
@@ -0,0 +1,67 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package annotate tests the annotate mode (see TestAnnotate), where the inferred annotations of
+// the exported API are suggested as fixes instead of reporting errors.
+package annotate
+
+// Global is assigned nil below.
+var Global *int //want "Inferred annotations for global variable\\(s\\) `Global`: // nilable\\(Global\\)"
+
+var (
+	A *int //want "Inferred annotations for global variable\\(s\\) `A`: // nilable\\(A\\)"
+	B = new(int)
+)
+
+var unexported *int
+
+func Reset() {
+	Global = nil
+	A = nil
+	unexported = nil
+}
+
+// S has an exported field that is assigned nil.
+type S struct { //want "Inferred annotations for fields of struct `S`: // nilable\\(F\\)"
+	F *int
+	G *int
+}
+
+// Clear clears the field F.
+func (s *S) Clear() {
+	s.F = nil
+}
+
+// NilReturn returns a nilable pointer.
+func NilReturn() *int { //want "Inferred annotations for function `NilReturn`: // nilable\\(result 0\\)"
+	return nil
+}
+
+func NilParam(p *int, q *int) { //want "Inferred annotations for function `NilParam`: // nilable\\(p\\)"
+	print(p, q)
+}
+
+// nilable(result 0)
+func Annotated() *int {
+	return nil
+}
+
+func Names() []string { //want "Inferred annotations for function `Names`: // nonnil\\(result 0\\)"
+	return []string{"a"}
+}
+
+func use() {
+	NilParam(nil, new(int))
+	_ = Names()[0]
+}
@@ -0,0 +1,87 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This package aims to test the annotations and contracts written on the line right above the
+// anonymous functions.
+package annotations
+
+type Request struct {
+	path string
+}
+
+type Handler struct {
+	serve func(r *Request) string
+}
+
+func testAnnotatedParams() {
+	// nilable(r)
+	serve := func(r *Request) string {
+		// ERROR_GROUP: the errors reporting the nilable values annotated for the anonymous functions
+		// are grouped together and reported on the below line.
+		return r.path //want "annotated as so"
+	}
+	print(serve(&Request{}))
+
+	// nilable(r)
+	safeServe := func(r *Request) string {
+		if r == nil {
+			return ""
+		}
+		return r.path
+	}
+	print(safeServe(nil))
+
+	h := &Handler{
+		// nilable(r)
+		serve: func(r *Request) string {
+			return r.path // (error here is grouped with the error at line marked with `ERROR_GROUP`)
+		},
+	}
+	print(h.serve(&Request{}))
+}
+
+func testAnnotatedResults() {
+	// nilable(result 0)
+	newRequest := func(path string) *Request {
+		if path == "" {
+			return nil
+		}
+		return &Request{path: path}
+	}
+	print(newRequest("/").path) // (error here is grouped with the error at line marked with `ERROR_GROUP`)
+}
+
+func testContracts() {
+	// contract(nonnil -> nonnil)
+	wrap := func(r *Request) *Request {
+		if r == nil {
+			return nil
+		}
+		return &Request{path: r.path}
+	}
+	print(wrap(&Request{}).path)
+
+	var r *Request
+	print(wrap(r).path) //want "returned from `__anonymousFunction.*` in position 0"
+}
+
+// The trailing comment of the code on the line right above an anonymous function is not its doc
+// comment.
+func testTrailingComment() {
+	count := 0 // nonnil(result 0)
+	find := func(path string) *Request {
+		return nil
+	}
+	print(count, find("/") == nil)
+}
@@ -0,0 +1,44 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This package aims to test nilability behavior for simple cases in anonymous functions.
+package anonymousfunction
+
+type C struct {
+	f int
+}
+
+func derefC(c *C) {
+	print(c.f) //want "passed as param 0 to callback `fn`"
+}
+
+// Here we test that the function-typed params of anonymous functions (i.e., callbacks) are tracked
+// like those of the declared functions, but not the variables in the closure.
+func testCallbacks() {
+	func(fn func(*C)) {
+		fn(nil)
+	}(derefC)
+
+	func(fn func(*C)) {
+		var c *C
+		fn(c)
+	}(func(c *C) {
+		print(c.f) //want "passed as param 0 to callback `fn`"
+	})
+
+	fn := derefC
+	func() {
+		fn(nil)
+	}()
+}
@@ -0,0 +1,170 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package callbacks tests the nilability annotations on the params and results of function-typed
+params and struct fields (i.e., callbacks), e.g., `// nilable(fn.param 0)`.
+
+<nilaway no inference>
+*/
+package callbacks
+
+var dummy bool
+
+type T struct {
+	f int
+}
+
+// nilable(fn.param 0)
+func forEachMaybe(ts []*T, fn func(*T)) {
+	for _, t := range ts {
+		fn(t)
+	}
+	fn(nil)
+}
+
+func forEach(ts []*T, fn func(*T)) {
+	for _, t := range ts {
+		fn(t)
+	}
+	fn(nil) //want "passed as param 0 to callback"
+}
+
+func deref(t *T) {
+	print(t.f)
+}
+
+// nilable(t)
+func derefSafe(t *T) {
+	if t != nil {
+		print(t.f)
+	}
+}
+
+type printer struct{}
+
+func (printer) deref(t *T) {
+	print(t.f)
+}
+
+func testForEach(ts []*T) {
+	forEachMaybe(ts, deref) //want "param 0 of callback `fn`"
+	forEachMaybe(ts, derefSafe)
+	forEachMaybe(ts, printer{}.deref) //want "param 0 of callback `fn`"
+	forEach(ts, deref)
+	forEach(ts, derefSafe)
+}
+
+func (t *T) process() {
+	print(t.f)
+}
+
+// nilable(t)
+func (t *T) processSafe() {
+	if t != nil {
+		print(t.f)
+	}
+}
+
+func (t *T) merge(other *T) {
+	print(t.f + other.f)
+}
+
+// nilable(fn.param 1)
+func forEachPairMaybe(ts []*T, fn func(*T, *T)) {
+	for _, t := range ts {
+		fn(t, nil)
+	}
+}
+
+// For method expressions, param 0 of the callback is the receiver of the method.
+func testMethodExpr(ts []*T) {
+	forEachMaybe(ts, (*T).process) //want "param 0 of callback `fn`"
+	forEachMaybe(ts, (*T).processSafe)
+	forEach(ts, (*T).process)
+	forEachPairMaybe(ts, (*T).merge) //want "param 1 of callback `fn`"
+}
+
+// nilable(fn.result 0)
+func getMaybe(fn func() *T) int {
+	return fn().f //want "result 0 of callback `fn`"
+}
+
+func get(fn func() *T) int {
+	return fn().f
+}
+
+// nilable(result 0)
+func newMaybe() *T {
+	if dummy {
+		return nil
+	}
+	return &T{}
+}
+
+func newT() *T {
+	return &T{}
+}
+
+func testGet() {
+	print(getMaybe(newMaybe))
+	print(getMaybe(newT))
+	print(get(newMaybe)) //want "returned as result 0 from callback `fn`"
+	print(get(newT))
+}
+
+// nilable(fn.param 0)
+func passThroughMaybe(ts []*T, fn func(*T)) {
+	forEach(ts, fn)
+	forEachMaybe(ts, fn)
+}
+
+func passThrough(ts []*T, fn func(*T)) {
+	forEach(ts, fn)
+	forEachMaybe(ts, fn) //want "param 0 of callback `fn`"
+}
+
+// nilable(onItem.param 0, onDone.result 0)
+type handler struct {
+	onItem func(*T)
+	onDone func() *T
+}
+
+type strictHandler struct {
+	onItem func(*T)
+}
+
+func testFieldAssign(ts []*T) {
+	_ = handler{onItem: deref} //want "param 0 of callback field `onItem`"
+	_ = handler{onItem: derefSafe}
+	h := handler{}
+	h.onItem = deref //want "param 0 of callback field `onItem`"
+	h.onItem = derefSafe
+	h.onItem = (*T).process //want "param 0 of callback field `onItem`"
+	_ = strictHandler{deref}
+	s := strictHandler{}
+	s.onItem = derefSafe
+}
+
+func (h handler) run(ts []*T) int {
+	for _, t := range ts {
+		h.onItem(t)
+	}
+	h.onItem(nil)
+	return h.onDone().f //want "result 0 of callback field `onDone`"
+}
+
+func (s *strictHandler) run() {
+	s.onItem(nil) //want "passed as param 0 to callback field `onItem`"
+}
@@ -0,0 +1,64 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This package tests the configurations of the diagnostic categories (see TestMain), where the
+deep-read category is disabled and the field-access category is downgraded to warnings for this
+package.
+
+<nilaway no inference>
+*/
+package categories
+
+type A struct {
+	f int
+}
+
+// nilable(p)
+func directDeref(p *int) int {
+	return *p //want "\\[ptr-load\\]. Observed"
+}
+
+// nilable(a)
+func fieldAccess(a *A) int {
+	return a.f // reported as a warning (see TestCategories) instead of a diagnostic
+}
+
+// nilable(s[])
+func deepReadFromSlice(s []*int) int {
+	if len(s) > 0 {
+		return *s[0]
+	}
+	return 0
+}
+
+func deepReadFromMap(m map[int]*A) int {
+	return m[0].f
+}
+
+// nilable(p)
+func nilReceiver(p *A) {
+	p.ptrMethod() //want "\\[nil-receiver\\]"
+}
+
+func (a *A) ptrMethod() {}
+
+// nilable(result 0)
+func retNil() *int {
+	return nil
+}
+
+func nilableReturn() *int {
+	return retNil() //want "\\[nilable-return\\]"
+}
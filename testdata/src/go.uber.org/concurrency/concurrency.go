@@ -0,0 +1,117 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+These tests check the built-in models of the concurrency primitives in the standard library: the
+values loaded from `sync.Map` must be guarded by their `ok` results, and the values loaded from
+`atomic.Pointer[T]` and `atomic.Value` are nilable unless a store dominates the load.
+
+<nilaway no inference>
+*/
+package concurrency
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type T struct {
+	f int
+}
+
+type S struct {
+	p atomic.Pointer[T]
+	v atomic.Value
+}
+
+func syncMapLoad(m *sync.Map, i int) any {
+	switch i {
+	case 0:
+		v, _ := m.Load("k")
+		return v //want "lacking guarding"
+	case 1:
+		if v, ok := m.Load("k"); ok {
+			return v
+		}
+	case 2:
+		v, ok := m.Load("k")
+		if !ok {
+			return 0
+		}
+		return v
+	case 3:
+		v, _ := m.LoadAndDelete("k")
+		return v //want "lacking guarding"
+	case 4:
+		if v, loaded := m.LoadAndDelete("k"); loaded {
+			return v
+		}
+	case 5:
+		// The given value is stored and returned if no value was loaded.
+		v, _ := m.LoadOrStore("k", 1)
+		return v
+	}
+	return 0
+}
+
+func atomicPointerLoad(p *atomic.Pointer[T], s *S, t *T, i int) int {
+	switch i {
+	case 0:
+		return p.Load().f //want "trusted function"
+	case 1:
+		return s.p.Load().f //want "trusted function"
+	case 2:
+		if t := s.p.Load(); t != nil {
+			return t.f
+		}
+	case 3:
+		x := p.Load()
+		return x.f //want "trusted function"
+	case 4:
+		p.Store(&T{})
+		return p.Load().f
+	case 5:
+		s.p.Store(t)
+		return s.p.Load().f
+	case 6:
+		p.Store(nil)
+		return p.Load().f //want "literal `nil`"
+	case 7:
+		if t != nil {
+			s.p.Store(t)
+		}
+		return s.p.Load().f //want "trusted function"
+	case 8:
+		// A store to another receiver has no effect.
+		s.p.Store(t)
+		return p.Load().f //want "trusted function"
+	}
+	return 0
+}
+
+func atomicValueLoad(v *atomic.Value, s *S, i int) any {
+	switch i {
+	case 0:
+		return v.Load() //want "trusted function"
+	case 1:
+		return s.v.Load() //want "trusted function"
+	case 2:
+		s.v.Store(&T{})
+		return s.v.Load()
+	case 3:
+		v.Store(i)
+		return v.Load()
+	}
+	return 0
+}
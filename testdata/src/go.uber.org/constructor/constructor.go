@@ -0,0 +1,193 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package constructor tests that the unexported fields always set by the constructors of their
+// struct types (and never reassigned to nil or left unset elsewhere) are treated as non-nil.
+package constructor
+
+import "errors"
+
+var dummy bool
+
+type Logger struct{}
+
+func (*Logger) Log(string) {}
+
+type DB struct {
+	Name string
+}
+
+func getMaybeDB() *DB {
+	if dummy {
+		return nil
+	}
+	return &DB{}
+}
+
+type Service struct {
+	logger   *Logger //want "passed as arg `logger`"
+	db       *DB     //want "passed as arg `db`"
+	backup   *DB
+	fallback *DB
+}
+
+func NewService(logger *Logger, db *DB) *Service {
+	s := &Service{logger: logger, backup: &DB{}}
+	s.db = db
+	if dummy {
+		s.fallback = &DB{}
+	}
+	return s
+}
+
+func (s *Service) Run() {
+	// Fields always set by the constructor are non-nil.
+	s.logger.Log("run")
+	print(s.db.Name)
+	// backup is reassigned to nil elsewhere, and fallback is only conditionally set.
+	print(s.backup.Name)   //want "accessed field `Name`"
+	print(s.fallback.Name) //want "accessed field `Name`"
+}
+
+func (s *Service) Reset() {
+	s.backup = nil
+}
+
+func (s *Service) SetFallback() {
+	s.fallback = getMaybeDB()
+}
+
+func callers() {
+	// Since the fields are non-nil, the nil flows into them are reported (at the fields).
+	NewService(nil, &DB{})
+	NewService(&Logger{}, getMaybeDB())
+}
+
+type Client struct {
+	db *DB //want "assigned into field `db`"
+}
+
+// NewClient sets the field on all paths that return the client.
+func NewClient(fail bool) (*Client, error) {
+	if fail {
+		return nil, errors.New("failed")
+	}
+	return &Client{db: &DB{}}, nil
+}
+
+// NewClientWithDB is another constructor of Client that also sets the field.
+func NewClientWithDB(db *DB) *Client {
+	return &Client{db: db}
+}
+
+func (c *Client) Query() {
+	print(c.db.Name)
+}
+
+func (c *Client) Swap() {
+	c.db = getMaybeDB()
+}
+
+type Pool struct {
+	db *DB
+}
+
+// NewPool returns the pool before setting the field on one path.
+func NewPool() *Pool {
+	p := &Pool{}
+	if dummy {
+		return p
+	}
+	p.db = &DB{}
+	return p
+}
+
+func (p *Pool) Get() {
+	print(p.db.Name) //want "accessed field `Name`"
+}
+
+func (p *Pool) Swap() {
+	p.db = getMaybeDB()
+}
+
+type Worker struct {
+	logger *Logger
+	db     *DB
+	// Exported fields may be left unset by the composite literals in other packages.
+	Output *DB
+}
+
+func NewWorker(logger *Logger, db *DB) *Worker {
+	w := &Worker{logger: logger}
+	w.db = db
+	w.Output = db
+	return w
+}
+
+func (w *Worker) Run() {
+	// The fields left unset by the composite literals outside the constructors are not treated as
+	// non-nil, so the nil flows are reported at the reads of the fields.
+	print(w.db.Name)     //want "accessed field `Name`"
+	print(w.Output.Name) //want "accessed field `Name`"
+	w.logger.Log("run")
+}
+
+func newIdleWorker() *Worker {
+	return &Worker{logger: &Logger{}, Output: &DB{}}
+}
+
+type Job struct {
+	db *DB
+}
+
+func NewJob(db *DB) *Job {
+	j := &Job{}
+	j.db = db
+	return j
+}
+
+func (j *Job) Run() {
+	print(j.db.Name) //want "accessed field `Name`"
+}
+
+// zeroJob creates a zero value of Job outside the constructor.
+func zeroJob() Job {
+	var j Job
+	return j
+}
+
+type Task struct {
+	db *DB
+}
+
+func NewTask(db *DB) *Task {
+	t := &Task{}
+	t.db = db
+	return t
+}
+
+func (t *Task) Run() {
+	print(t.db.Name) //want "accessed field `Name`"
+}
+
+// newTask creates a zero value of Task outside the constructor.
+func newTask() *Task {
+	return new(Task)
+}
+
+func workerCallers() {
+	NewWorker(&Logger{}, getMaybeDB())
+	NewJob(getMaybeDB())
+	NewTask(getMaybeDB())
+}
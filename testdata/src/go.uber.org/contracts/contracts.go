@@ -143,7 +143,7 @@ func threeWayOneConcrete(m map[any]any) any {
 	return v
 }
 
-var getInt func() int
+var getInt = func() int { return 0 }
 
 var dummy2 bool
 
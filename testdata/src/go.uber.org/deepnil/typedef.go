@@ -0,0 +1,93 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deepnil
+
+// This file tests deep nilability annotations on typedefs of channel types and of generic types.
+
+type event struct {
+	name string
+}
+
+// nilable(<-events)
+type events chan *event
+
+type strictEvents chan *event
+
+// nonnil(e, s)
+func testChanTypedefRecv(e events, s strictEvents) string {
+	switch 0 {
+	case 1:
+		x := <-e
+		return x.name //want "received from a channel of type `events`"
+	case 2:
+		for x := range e {
+			return x.name //want "accessed field `name`"
+		}
+	case 3:
+		y := <-s
+		return y.name
+	case 4:
+		for y := range s {
+			return y.name
+		}
+	}
+	return ""
+}
+
+// nonnil(e, s)
+func testChanTypedefSend(e events, s strictEvents) {
+	e <- nil
+	s <- nil //want "sent to channel of deeply nonnil type `strictEvents`"
+}
+
+// nilable(cache[])
+type cache[K comparable, V any] map[K]*V
+
+type strictCache[K comparable, V any] map[K]*V
+
+// nilable(intCache[])
+type intCache cache[string, int]
+
+type strictIntCache strictCache[string, int]
+
+// nilable(intPtrs[])
+type intPtrs genericSlice[int]
+
+type genericSlice[T any] []*T
+
+// nonnil(c, s, ic, sc, p)
+func testGenericTypedefRead(c cache[string, int], s strictCache[string, int], ic intCache, sc strictIntCache, p intPtrs) int {
+	switch 0 {
+	case 1:
+		if v, ok := c["a"]; ok {
+			return *v //want "index of a map of type `cache`"
+		}
+	case 2:
+		if v, ok := s["a"]; ok {
+			return *v
+		}
+	case 3:
+		if v, ok := ic["a"]; ok {
+			return *v //want "index of a map of type `intCache`"
+		}
+	case 4:
+		if v, ok := sc["a"]; ok {
+			return *v
+		}
+	case 5:
+		return *p[0] //want "dereferenced"
+	}
+	return 0
+}
@@ -394,7 +394,7 @@ func retsNonnilNilableAWithErr() (*A, *A, error) {
 	return nil, nil, &myErr{}
 }
 
-var getInt func() int
+var getInt = func() int { return 0 }
 
 func testTrackingThroughDeeperExprParallel() {
 	a, b := &A{}, &A{}
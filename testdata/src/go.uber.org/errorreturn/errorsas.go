@@ -0,0 +1,108 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorreturn
+
+import "errors"
+
+type codeErr struct {
+	code int
+}
+
+func (*codeErr) Error() string { return "code error" }
+
+func (*codeErr) Code() int { return 0 }
+
+func testErrorsAs(err error, i int) int {
+	var ce *codeErr
+	switch i {
+	case 0:
+		if errors.As(err, &ce) {
+			return ce.code
+		}
+	case 1:
+		if !errors.As(err, &ce) {
+			return 0
+		}
+		return ce.code
+	case 2:
+		if dummy && errors.As(err, &ce) {
+			return ce.code
+		}
+	case 3:
+		ok := errors.As(err, &ce)
+		if ok {
+			return ce.code
+		}
+	case 4:
+		errors.As(err, &ce)
+		return ce.code //want "unassigned variable `ce`"
+	case 5:
+		if errors.As(err, &ce) {
+			return 0
+		}
+		return ce.code //want "unassigned variable `ce`"
+	case 6:
+		ok := errors.As(err, &ce)
+		ce = nil
+		if ok {
+			return ce.code //want "literal `nil`"
+		}
+	case 7:
+		// the target of errors.As must be a pointer to the variable to be tracked
+		target := &ce
+		if errors.As(err, target) {
+			return ce.code //want "unassigned variable `ce`"
+		}
+	}
+	return 0
+}
+
+func testTypeSwitchBinding(err error) int {
+	switch e := err.(type) {
+	case *codeErr:
+		return e.code
+	case interface{ Code() int }:
+		return e.Code()
+	}
+	return 0
+}
+
+func testTypeSwitchBindingNilable(err error) int {
+	switch e := err.(type) {
+	case *codeErr, nil:
+		// e has the type of err here, which may be nil
+		return len(e.Error()) //want "called `Error"
+	}
+	return 0
+}
+
+// nilable(result 0)
+func getAny() any {
+	return nil
+}
+
+func testTypeSwitchBindingNonPointer(i int) int {
+	// Unlike the pointers, the nil maps and slices are commonly stored in interfaces, so binding
+	// them in a type switch does not make them nonnil.
+	switch v := getAny().(type) {
+	case *codeErr:
+		return v.code
+	case []int:
+		return v[0] //want "sliced into"
+	case map[int]int:
+		v[i] = 0 //want "written to at an index"
+	}
+	return 0
+}
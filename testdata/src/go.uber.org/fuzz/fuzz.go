@@ -0,0 +1,28 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fuzz is the template package for fuzzing NilAway, whose source is replaced by the fuzz
+// inputs. The inputs may only import the packages imported here.
+package fuzz
+
+import "errors"
+
+var errNil = errors.New("nil")
+
+func f(p *int) (*int, error) {
+	if p == nil {
+		return nil, errNil
+	}
+	return p, nil
+}
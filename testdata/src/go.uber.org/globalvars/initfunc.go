@@ -0,0 +1,114 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+These tests check that the global variables definitely assigned in init() functions are not
+considered nil, except when they are read by the code that may run before the assignments, i.e.,
+the initialization of package-level variables, the earlier init() functions, and the statements
+preceding the assignments (along with the functions called by them).
+
+<nilaway no inference>
+*/
+package globalvars
+
+var assignedInInit *int
+
+var initializedWithValue = new(int)
+
+var assignedInSecondInit, assignedInSecondInitTuple *int
+
+var conditionallyAssigned *int //want "assigned into global variable"
+
+var assignedAfterReturn *int //want "assigned into global variable"
+
+var assignedInClosure *int //want "assigned into global variable"
+
+var readBeforeInit = assignedInInit //want "read before it is assigned in init()"
+
+// nilable(nilableAssignedInInit)
+var nilableAssignedInInit *int
+
+var nonnilAssignedNil *int
+
+func init() {
+	assignedInInit = new(int)
+	nilableAssignedInInit = nil
+	nonnilAssignedNil = nil //want "assigned into global variable"
+	func() {
+		assignedInClosure = new(int)
+	}()
+	if *assignedInInit > 0 {
+		conditionallyAssigned = new(int)
+	}
+	if initializedWithValue == nil {
+		return
+	}
+	assignedAfterReturn = new(int)
+}
+
+func init() {
+	assignedInSecondInit, assignedInSecondInitTuple = newIntPair()
+}
+
+func newIntPair() (*int, *int) {
+	return new(int), new(int)
+}
+
+func useInitAssigned() int {
+	return *assignedInInit + *assignedInSecondInit + *assignedInSecondInitTuple + *conditionallyAssigned +
+		*assignedAfterReturn + *assignedInClosure + *readBeforeInit +
+		*nilableAssignedInInit + *nonnilAssignedNil //want "dereferenced"
+}
+
+// The variables below are assigned in init() functions, but read by the code that may run before
+// the assignments.
+
+var readViaCall = derefAssignedLater()
+
+var readViaSelector = assignedForSelector.f //want "read before it is assigned in init()"
+
+var assignedLater *int
+
+type box struct {
+	f *int
+}
+
+var assignedForSelector *box
+
+func derefAssignedLater() int {
+	return *assignedLater //want "read before it is assigned in init()"
+}
+
+var readInInitFirst, readByHelperFirst, readAfterAssigned, assignedInLaterFile *int
+
+func init() {
+	print(*readInInitFirst) //want "read before it is assigned in init()"
+	readInInitFirst = new(int)
+	readByHelper()
+	readByHelperFirst = new(int)
+	readAfterAssigned = new(int)
+	// The helpers called after the assignments can read the variables.
+	readByHelperAfter()
+	assignedLater = new(int)
+	assignedForSelector = &box{f: new(int)}
+	assignedInLaterFile = new(int)
+}
+
+func readByHelper() {
+	print(*readByHelperFirst) //want "read before it is assigned in init()"
+}
+
+func readByHelperAfter() {
+	print(*readAfterAssigned)
+}
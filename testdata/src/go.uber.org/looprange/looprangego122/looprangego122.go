@@ -38,3 +38,14 @@ func testRangeOverBasicTypes(j int) {
 		}
 	}
 }
+
+type N int
+
+// Test for checking range over named integer types.
+func testRangeOverNamedInt(n N) {
+	var p *int
+	for i := range n {
+		print(i)
+		print(*p) //want "dereferenced"
+	}
+}
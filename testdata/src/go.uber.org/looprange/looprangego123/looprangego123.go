@@ -0,0 +1,123 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// <nilaway no inference>
+package looprangego123
+
+import "iter"
+
+type T struct {
+	f int
+}
+
+var dummy bool
+
+// nilable(yield.param 1)
+func all(yield func(int, *T) bool) {
+	for i := range 10 {
+		if !yield(i, nil) {
+			return
+		}
+	}
+}
+
+func allNonnil(yield func(int, *T) bool) {
+	if !yield(0, &T{}) {
+		return
+	}
+	yield(1, nil) //want "passed as param 1 to callback `yield`"
+}
+
+// nonnil(elems)
+type List struct {
+	elems []*T
+}
+
+// nilable(yield.param 0)
+func (l *List) backward(yield func(*T) bool) {
+	for i := len(l.elems) - 1; i >= 0; i-- {
+		if !yield(l.elems[i]) {
+			return
+		}
+	}
+}
+
+func (l *List) seq() func(yield func(*T) bool) {
+	return l.backward
+}
+
+// Test for checking range over functions (i.e., iterators), where the nilability of the yielded
+// values is given by the annotations of the `yield` param of the iterator.
+// TODO: move this testcase to `looprange.go` once NilAway starts to support Go 1.23.
+func testRangeOverFunc(l *List, j int) {
+	switch j {
+	case 0:
+		for i, t := range all {
+			print(i)
+			print(t.f) //want "param 1 of callback `yield`"
+		}
+	case 1:
+		for _, t := range all {
+			if t != nil {
+				print(t.f)
+			}
+		}
+	case 2:
+		for i := range all {
+			print(i)
+		}
+	case 3:
+		for _, t := range allNonnil {
+			print(t.f)
+		}
+	case 4:
+		for t := range l.backward {
+			print(t.f) //want "param 0 of callback `yield`"
+		}
+	case 5:
+		// iterators returned by calls are not tracked
+		for t := range l.seq() {
+			print(t.f)
+		}
+	}
+}
+
+// nilable(yield.param 0)
+func keys(yield func(*T, int) bool) {
+	yield(nil, 0)
+}
+
+// Test for checking range over the named iterator types `iter.Seq` and `iter.Seq2`, whose values
+// are the declared iterators above.
+func testRangeOverIterSeq(l *List, j int) {
+	switch j {
+	case 0:
+		for t := range iter.Seq[*T](l.backward) {
+			print(t.f) //want "param 0 of callback `yield`"
+		}
+	case 1:
+		for _, t := range iter.Seq2[int, *T](all) {
+			print(t.f) //want "param 1 of callback `yield`"
+		}
+	case 2:
+		for t, i := range iter.Seq2[*T, int](keys) {
+			print(i)
+			print(t.f) //want "param 0 of callback `yield`"
+		}
+	case 3:
+		for _, t := range iter.Seq2[int, *T](allNonnil) {
+			print(t.f)
+		}
+	}
+}
@@ -904,31 +904,28 @@ func testNonLiteralMapAccess(mp map[int]*int, i, j int) {
 		}
 
 	case 11:
-		// TODO: This case is currently a false negative since NilAway does not track the value of integers (`i`).
-		//  However, this is not expected to be a common pattern, hence we plan to add support for this in a follow-up PR.
+		// The index `i` is reassigned between the accesses, so the check does not guard the read.
 		i = 0
 		if mp[i] != nil {
 			i = 100
-			print(*mp[i]) // TODO: report error here
+			print(*mp[i]) //want "lacking guarding"
 		}
 
 	case 12:
-		// TODO: Similar as above, this case is currently a false negative since NilAway does not track the value of integers (`i`).
-		//  However, this is not expected to be a common pattern, hence we plan to add support for this in a follow-up PR.
+		// Similar as above.
 		i = len(mp) - 1
 		if mp[i] != nil {
 			i = len(mp)
-			print(*mp[i]) // TODO: report error here
+			print(*mp[i]) //want "lacking guarding"
 		}
 
 	case 13:
-		// TODO: Similar as above, this case is currently a false negative since NilAway does not track the value of integers (`i`).
-		//  However, this is not expected to be a common pattern, hence we plan to add support for this in a follow-up PR.
+		// Similar as above.
 		a := &A{}
 		i = a.f
 		if mp[i] != nil {
 			i = a.g
-			print(*mp[i]) // TODO: report error here
+			print(*mp[i]) //want "lacking guarding"
 		}
 
 	case 14:
@@ -1021,3 +1018,69 @@ func testNestedMaps(mapOfMap map[string]map[string]*int, mapOfmapOfMap map[strin
 		}
 	}
 }
+
+// tests for reading a map at the same index after writing to it
+
+// nonnil(mp, mp[])
+func testWriteThenRead(mp map[string]*int, k, j string, b []byte, keys []string) {
+	switch 0 {
+	case 1:
+		mp[k] = new(int)
+		print(*mp[k])
+
+	case 2:
+		if _, ok := mp[k]; !ok {
+			mp[k] = new(int)
+		}
+		print(*mp[k])
+
+	case 3:
+		if mp[k] == nil {
+			mp[k] = new(int)
+		}
+		print(*mp[k])
+
+	case 4:
+		mp[string(b)] = new(int)
+		print(*mp[string(b)])
+
+	case 5:
+		for _, key := range keys {
+			mp[key] = new(int)
+			print(*mp[key])
+		}
+
+	case 6:
+		// the write may be for a different index
+		mp[k] = new(int)
+		print(*mp[j]) //want "lacking guarding"
+
+	case 7:
+		// the index is reassigned between the write and the read
+		mp[k] = new(int)
+		k = j
+		print(*mp[k]) //want "lacking guarding"
+
+	case 8:
+		// the index is deleted (or may be, for a different index) between the write and the read
+		mp[k] = new(int)
+		delete(mp, k)
+		print(*mp[k]) //want "lacking guarding"
+
+	case 9:
+		mp[k] = new(int)
+		delete(mp, j)
+		print(*mp[k]) //want "lacking guarding"
+
+	case 10:
+		mp[k] = new(int)
+		clear(mp)
+		print(*mp[k]) //want "lacking guarding"
+
+	case 11:
+		// writes after the deletion are still tracked
+		delete(mp, k)
+		mp[k] = new(int)
+		print(*mp[k])
+	}
+}
@@ -0,0 +1,133 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This test file checks the sites of casts that are nested in composite literals, or that go
+// through containers (maps, channels, variadic parameters, and appends into named slice types),
+// as well as the casts in the return statements of anonymous functions.
+// <nilaway no inference>
+
+package methodimplementation
+
+// Nested composite literals with elided types.
+
+type I141 interface {
+	// nilable(x)
+	foo(x *A141) *A141 //want "returned as result"
+}
+
+type A141 struct{}
+
+// nilable(result 0)
+func (A141) foo(x *A141) *A141 { //want "passed as param"
+	return nil
+}
+
+func m141() {
+	s := [][]I141{{&A141{}}}
+	print(s)
+}
+
+// Map values that are nested composite literals.
+
+type I142 interface {
+	// nilable(x)
+	foo(x *A142) *A142 //want "returned as result"
+}
+
+type A142 struct{}
+
+// nilable(result 0)
+func (A142) foo(x *A142) *A142 { //want "passed as param"
+	return nil
+}
+
+func m142() {
+	m := map[string][]I142{"a": {&A142{}}}
+	print(m)
+}
+
+// Channel sends.
+
+type I143 interface {
+	// nilable(x)
+	foo(x *A143) *A143 //want "returned as result"
+}
+
+type A143 struct{}
+
+// nilable(result 0)
+func (A143) foo(x *A143) *A143 { //want "passed as param"
+	return nil
+}
+
+func m143(ch chan I143) {
+	ch <- &A143{}
+}
+
+// Appends into named slice types of interfaces, and variadic parameters.
+
+type I144 interface {
+	// nilable(x)
+	foo(x *A144) *A144 //want "returned as result"
+}
+
+type A144 struct{}
+
+// nilable(result 0)
+func (A144) foo(x *A144) *A144 { //want "passed as param"
+	return nil
+}
+
+type I144s []I144
+
+func m144(s I144s) I144s {
+	return append(s, &A144{})
+}
+
+type I145 interface {
+	// nilable(x)
+	foo(x *A145) *A145 //want "returned as result"
+}
+
+type A145 struct{}
+
+// nilable(result 0)
+func (A145) foo(x *A145) *A145 { //want "passed as param"
+	return nil
+}
+
+func takesI145(is ...I145) {}
+
+func m145() {
+	takesI145(&A145{}, &A145{})
+}
+
+// Returns in anonymous functions.
+
+type I146 interface {
+	// nilable(x)
+	foo(x *A146) *A146 //want "returned as result"
+}
+
+type A146 struct{}
+
+// nilable(result 0)
+func (A146) foo(x *A146) *A146 { //want "passed as param"
+	return nil
+}
+
+func m146() {
+	f := func() I146 { return &A146{} }
+	f()
+}
@@ -0,0 +1,91 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nilabletypes
+
+// This file tests that values of function type are treated as nilable, and that calling through
+// them is checked.
+
+// nilable(onDone)
+type handler struct {
+	onDone func()
+	run    func() int
+}
+
+func (h *handler) method() {}
+
+type runner interface {
+	method()
+}
+
+func callOptionalCallback(h *handler) {
+	h.onDone() //want "called as a function"
+	if h.onDone != nil {
+		h.onDone()
+	}
+	h.run()
+	h.method()
+}
+
+func assignNilCallback(h *handler) {
+	h.run = nil //want "assigned into field `run`"
+	h.onDone = nil
+}
+
+// nilable(cb)
+func callNilableParam(cb func(int) int, other func()) {
+	cb(1) //want "called as a function"
+	if cb != nil {
+		cb(2)
+	}
+	other()
+}
+
+func callUnassignedLocal() {
+	var f func()
+	f() //want "called as a function"
+
+	g := func() {}
+	g()
+
+	var h func()
+	h = callUnassignedLocal
+	h()
+
+	func() {}()
+}
+
+// nilable(handlers[])
+func callFromMap(handlers map[string]func(), k string) {
+	handlers[k]() //want "called as a function"
+	if h := handlers[k]; h != nil {
+		h()
+	}
+}
+
+// nilable(result 0)
+func retsNilableFunc() func() {
+	return nil
+}
+
+func callReturnedFunc(r runner) {
+	retsNilableFunc()() //want "called as a function"
+	r.method()
+	identity[int](1)
+	_ = int64(identity(2))
+}
+
+func identity[T any](t T) T {
+	return t
+}
@@ -0,0 +1,119 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// These tests check that nil checks whose results are stored in boolean variables guard later
+// dereferences when the variables are branched on, as long as nothing in between invalidates them.
+
+package nilcheck
+
+// nilable(name)
+type user struct {
+	name *string
+}
+
+// nilable(u)
+func testBoolVar(u *user, i int) string {
+	switch i {
+	case 0:
+		hasUser := u != nil
+		if hasUser {
+			return *u.name //want "dereferenced"
+		}
+	case 1:
+		valid := u != nil && u.name != nil
+		if !valid {
+			return ""
+		}
+		return *u.name
+	case 2:
+		valid := u != nil && u.name != nil
+		if valid == false {
+			return ""
+		}
+		return *u.name
+	case 3:
+		missing := u == nil || u.name == nil
+		if missing {
+			return ""
+		}
+		return *u.name
+	case 4:
+		var missing = !(u != nil && u.name != nil)
+		if !missing {
+			return *u.name
+		}
+	case 5:
+		hasUser, hasName := u != nil, u != nil && u.name != nil
+		if hasUser {
+			noop()
+		}
+		if hasName {
+			return *u.name
+		}
+	case 6:
+		hasUser := u != nil
+		if !hasUser {
+			return *u.name //want "accessed field" "dereferenced"
+		}
+	case 7:
+		// the check is not in effect when the variable is reassigned
+		hasUser := u != nil
+		if dummy {
+			hasUser = true
+		}
+		if hasUser {
+			return *u.name //want "accessed field" "dereferenced"
+		}
+	case 8:
+		// the check is not in effect when a checked variable is reassigned
+		valid := u != nil && u.name != nil
+		u = &user{}
+		if valid {
+			return *u.name //want "dereferenced"
+		}
+	case 9:
+		hasUser := u != nil
+		u = nil
+		if hasUser {
+			return *u.name //want "accessed field" "dereferenced"
+		}
+	case 10:
+		// the check survives control flow that does not touch the variables involved
+		valid := u != nil && u.name != nil
+		for j := 0; j < i; j++ {
+			noop()
+		}
+		if valid {
+			return *u.name
+		}
+	}
+	return ""
+}
+
+func testBoolVarFieldWrite(u *user) string {
+	hasName := u.name != nil
+	if hasName {
+		noop()
+	}
+	if hasName {
+		return *u.name
+	}
+	hasName = u.name != nil
+	// the check is not in effect when a checked field is reassigned
+	u.name = nil
+	if hasName {
+		return *u.name //want "dereferenced"
+	}
+	return ""
+}
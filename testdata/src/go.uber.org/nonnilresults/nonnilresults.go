@@ -0,0 +1,81 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nonnilresults tests the always-nonnil and error-nonnil annotations, which allow the
+// results of error-returning and ok-returning functions to escape from guarding.
+package nonnilresults
+
+import (
+	"errors"
+
+	"go.uber.org/nonnilresults/upstream"
+)
+
+func useUpstream() {
+	c1, _ := upstream.NewClient(true)
+	print(c1.Name)
+
+	c2, _ := upstream.NewNamedClient(true)
+	print(c2.Name)
+
+	c3, _ := upstream.NewStubbedClient(true)
+	print(c3.Name)
+
+	c4, _ := upstream.NewGuardedClient(true)
+	print(c4.Name) //want "lacking guarding"
+}
+
+// always-nonnil(result 0)
+func newLocal(fail bool) (*upstream.Client, error) { //want "literal `nil` returned from `newLocal\\(\\)` in position 0"
+	if fail {
+		return nil, errors.New("failed")
+	}
+	return &upstream.Client{}, nil
+}
+
+// always-nonnil(result 0)
+func newLocalUnknownErr(err error) (*upstream.Client, error) { //want "literal `nil` returned from `newLocalUnknownErr\\(\\)` in position 0"
+	return nil, err
+}
+
+// always-nonnil(result 0)
+func lookup(ok bool) (*upstream.Client, bool) { //want "literal `nil` returned from `lookup\\(\\)` in position 0"
+	if !ok {
+		return nil, false
+	}
+	return &upstream.Client{}, true
+}
+
+// error-nonnil has no effect on ok-returning functions.
+// error-nonnil(result 0)
+func lookupErrorNonNil(ok bool) (*upstream.Client, bool) {
+	if !ok {
+		return nil, false
+	}
+	return &upstream.Client{}, true
+}
+
+func useLocal() {
+	c1, _ := newLocal(true)
+	print(c1.Name)
+
+	c2, _ := newLocalUnknownErr(nil)
+	print(c2.Name)
+
+	c3, _ := lookup(false)
+	print(c3.Name)
+
+	c4, _ := lookupErrorNonNil(false)
+	print(c4.Name) //want "lacking guarding"
+}
@@ -0,0 +1,59 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package upstream declares constructors whose results are annotated to escape from the guarding
+// by their error results, either in their doc comments or in a stub file.
+package upstream
+
+import "errors"
+
+type Client struct {
+	Name string
+}
+
+// NewClient always returns a usable client, even when it fails to connect.
+// always-nonnil(result 0)
+func NewClient(fail bool) (*Client, error) {
+	c := &Client{}
+	if fail {
+		return c, errors.New("failed to connect")
+	}
+	return c, nil
+}
+
+// NewNamedClient refers to its annotated result by name.
+// error-nonnil(c)
+func NewNamedClient(fail bool) (c *Client, err error) {
+	c = &Client{}
+	if fail {
+		err = errors.New("failed to connect")
+	}
+	return c, err
+}
+
+// NewStubbedClient is annotated in the stub file instead.
+func NewStubbedClient(fail bool) (*Client, error) {
+	if fail {
+		return &Client{}, errors.New("failed to connect")
+	}
+	return &Client{}, nil
+}
+
+// NewGuardedClient is not annotated, so its result is guarded as usual.
+func NewGuardedClient(fail bool) (*Client, error) {
+	if fail {
+		return nil, errors.New("failed to connect")
+	}
+	return &Client{}, nil
+}
@@ -0,0 +1,83 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file tests the implicit dereferences of embedded pointer fields for promoted fields and
+// methods, e.g., `s.f` is in fact `s.Base.f` if `f` is declared in the embedded field `*Base` of `s`.
+
+package receivers
+
+type Base struct {
+	f string
+}
+
+func (b *Base) baseField() string {
+	return b.f
+}
+
+// nilable(b)
+func (b *Base) nilSafeBaseField() string {
+	if b == nil {
+		return ""
+	}
+	return b.f
+}
+
+// nilable(Base)
+type Derived struct {
+	*Base
+}
+
+type NonnilDerived struct {
+	*Base
+}
+
+type Nested struct {
+	Derived
+}
+
+func testPromotedField(d *Derived, n *NonnilDerived, nested *Nested) string {
+	switch len(n.f) {
+	case 0:
+		return d.f //want "field `Base` accessed field `f`"
+	case 1:
+		if d.Base != nil {
+			return d.f
+		}
+		return ""
+	case 2:
+		return n.f
+	case 3:
+		return nested.f //want "field `Base` accessed field `f`"
+	case 4:
+		return nested.Derived.f //want "field `Base` accessed field `f`"
+	default:
+		return d.Base.f //want "field `Base` accessed field `f`"
+	}
+}
+
+func testPromotedMethod(d *Derived, n *NonnilDerived) string {
+	switch len(n.f) {
+	case 0:
+		return d.baseField() //want "field `Base`"
+	case 1:
+		if d.Base != nil {
+			return d.baseField()
+		}
+		return ""
+	case 2:
+		return d.nilSafeBaseField()
+	default:
+		return n.baseField()
+	}
+}
@@ -0,0 +1,47 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package repro contains a diagnostic among unrelated code to test the minimization of reproducers.
+package repro
+
+import (
+	"strconv"
+	"strings"
+)
+
+var counter int
+
+func describe() string {
+	return strings.Repeat(strconv.Itoa(counter), 2)
+}
+
+func find(k string) *int {
+	counter++
+	if k == "" {
+		return nil
+	}
+	v := len(k)
+	return &v
+}
+
+func use(k string) int {
+	counter--
+	if counter > 10 {
+		counter = 0
+	}
+	p := find(k)
+	name := describe()
+	_ = name
+	return *p
+}
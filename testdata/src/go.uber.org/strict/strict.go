@@ -0,0 +1,112 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This package is analyzed in strict mode (see TestMain), where the optimistic assumptions that
+NilAway makes by default are disabled. The non-strict counterparts of these tests can be found in
+the slices test package.
+
+<nilaway no inference>
+*/
+package strict
+
+type A struct {
+	f int
+}
+
+func dummyBool() bool { return true }
+
+// this function tests that double len equality checks are not interpreted as producing non-nil
+func testDoubleLenCheck(a, b []int) int {
+	switch 0 {
+	case 1:
+		if len(a) == len(b) {
+			return a[0] //want "sliced into"
+		}
+	case 2:
+		if len(a) != len(b) {
+			return 0
+		}
+		return b[0] //want "sliced into"
+	case 3:
+		if len(a) == len(b) && len(b) > 0 {
+			return b[0]
+		}
+	}
+	return 0
+}
+
+// this function tests that non-literal integers in length checks are not assumed to be positive
+func testNonLiteralLenCheck(a []int, i int) int {
+	const k = 1
+	switch 0 {
+	case 1:
+		if len(a) > i {
+			return a[0] //want "sliced into"
+		}
+	case 2:
+		if len(a) >= i {
+			return a[0] //want "sliced into"
+		}
+	case 3:
+		if len(a) == i {
+			return a[0] //want "sliced into"
+		}
+	case 4:
+		// literal integers are still interpreted precisely, but named constants are not
+		if len(a) > 0 {
+			return a[0]
+		}
+	case 5:
+		if len(a) == 3 {
+			return a[0]
+		}
+	case 6:
+		if len(a) >= k {
+			return a[0] //want "sliced into"
+		}
+	}
+	return 0
+}
+
+// this function tests that expressions whose nilability is not modeled are treated as nilable
+func testUnhandledExpr(x any, f func() func() *A) int {
+	switch 0 {
+	case 1:
+		return x.(*A).f //want "unhandled expression"
+	case 2:
+		if a := x.(*A); a != nil {
+			return a.f
+		}
+	case 3:
+		return f()().f //want "unhandled expression"
+	case 4:
+		var i int
+		p := &i
+		return *p
+	case 5:
+		a := &A{}
+		return a.f
+	case 6:
+		g := func() int { return 0 }
+		return g()
+	case 7:
+		// similar to the type assertions, the bindings of the type switches are unhandled
+		switch a := x.(type) {
+		case *A:
+			return a.f //want "unhandled expression"
+		}
+	}
+	return 0
+}
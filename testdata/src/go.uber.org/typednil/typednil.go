@@ -0,0 +1,108 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package typednil tests the detection of nil pointers stored in interfaces that are compared
+// against nil, i.e., "typed nils" that make `err != nil` true.
+package typednil
+
+type myErr struct{}
+
+func (*myErr) Error() string { return "myErr" }
+
+type stringer interface {
+	String() string
+}
+
+type myStringer struct{}
+
+func (*myStringer) String() string { return "myStringer" }
+
+var dummy bool
+
+func retTypedNil() error {
+	var e *myErr
+	return e //want "typed nil makes it non-nil"
+}
+
+func retTypedNilWithResult() (*int, error) {
+	var e *myErr
+	if dummy {
+		return new(int), nil
+	}
+	return nil, e //want "typed nil makes it non-nil"
+}
+
+func retNonNil() error {
+	e := &myErr{}
+	return e
+}
+
+func retMaybeNil() error {
+	var e *myErr
+	if dummy {
+		e = &myErr{}
+	}
+	return e //want "typed nil makes it non-nil"
+}
+
+func retChecked() error {
+	var e *myErr
+	if dummy {
+		e = &myErr{}
+	}
+	if e == nil {
+		return nil
+	}
+	return e
+}
+
+func assignCompared() {
+	var e *myErr
+	var err error = e //want "typed nil makes it non-nil"
+	if err != nil {
+		print(err.Error())
+	}
+}
+
+func assignNotCompared() {
+	var e *myErr
+	var err error = e
+	print(err)
+}
+
+func takesComparedErr(err error) {
+	if err == nil {
+		return
+	}
+	print(err.Error())
+}
+
+func takesStringer(s stringer) {
+	print(s)
+}
+
+func passArgs() {
+	var e *myErr
+	takesComparedErr(e) //want "typed nil makes it non-nil"
+	takesComparedErr(&myErr{})
+
+	var s *myStringer
+	takesStringer(s)
+}
+
+func namedReturn() (err error) {
+	var e *myErr
+	err = e //want "typed nil makes it non-nil"
+	return
+}
@@ -0,0 +1,28 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reader dereferences the field of upstream.S, which conflicts with the nil written by
+// the sibling package writer.
+package reader
+
+import "go.uber.org/wholeprogram/upstream"
+
+func Read(s *upstream.S) int {
+	return *s.P // reported by the global pass only
+}
+
+func Local() int {
+	var p *int
+	return *p // reported by the modular analysis
+}
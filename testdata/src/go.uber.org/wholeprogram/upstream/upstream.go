@@ -0,0 +1,22 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package upstream is imported by the sibling packages writer and reader, which no package
+// imports together, to test the global inference pass of the whole-program driver.
+package upstream
+
+// S is a struct whose field is determined nilable by writer and nonnil by reader.
+type S struct {
+	P *int
+}
@@ -243,7 +243,7 @@ func IsSliceAppendCall(node *ast.CallExpr, pass *analysis.Pass) (*types.Slice, b
 	if funcName, ok := node.Fun.(*ast.Ident); ok {
 		if declObj := pass.TypesInfo.Uses[funcName]; declObj != nil {
 			if declObj.String() == "builtin append" {
-				if sliceType, ok := pass.TypesInfo.TypeOf(node.Args[0]).(*types.Slice); ok {
+				if sliceType, ok := pass.TypesInfo.TypeOf(node.Args[0]).Underlying().(*types.Slice); ok {
 					return sliceType, true
 				}
 			}
@@ -264,7 +264,7 @@ func TypeBarsNilness(t types.Type) bool {
 	case *types.Tuple:
 		return false
 	case *types.Signature:
-		return true // function-types are not inhabited by nil
+		return false // function-typed values (e.g., unset callbacks) can be nil
 	case *types.Map:
 		return false
 	case *types.Chan:
@@ -446,8 +446,17 @@ var nilabilityPattern = regexp.MustCompile(`([\(|^\t](?i)(found\s|must\sbe\s)(ni
 
 // PrettyPrintErrorMessage is used in error reporting to post process and pretty print the output with colors
 func PrettyPrintErrorMessage(msg string) string {
+	return prettyPrintMessage(msg, fmt.Sprintf("\x1b[%dm%s\x1b[0m", 31, "error: ")) // red
+}
+
+// PrettyPrintWarningMessage is the same as PrettyPrintErrorMessage, except that the message is
+// marked as a warning (e.g., for downgraded diagnostics).
+func PrettyPrintWarningMessage(msg string) string {
+	return prettyPrintMessage(msg, fmt.Sprintf("\x1b[%dm%s\x1b[0m", 33, "warning: ")) // yellow
+}
+
+func prettyPrintMessage(msg string, severityStr string) string {
 	// TODO: below string parsing should not be required after  is implemented
-	errorStr := fmt.Sprintf("\x1b[%dm%s\x1b[0m", 31, "error: ")      // red
 	codeStr := fmt.Sprintf("\u001B[%dm%s\u001B[0m", 95, "`${1}`")    // magenta
 	pathStr := fmt.Sprintf("\u001B[%dm%s\u001B[0m", 36, "${1}")      // cyan
 	nilabilityStr := fmt.Sprintf("\u001B[%dm%s\u001B[0m", 1, "${1}") // bold
@@ -455,7 +464,7 @@ func PrettyPrintErrorMessage(msg string) string {
 	msg = nilabilityPattern.ReplaceAllString(msg, nilabilityStr)
 	msg = codeReferencePattern.ReplaceAllString(msg, codeStr)
 	msg = pathPattern.ReplaceAllString(msg, pathStr)
-	msg = errorStr + msg
+	msg = severityStr + msg
 	return msg
 }
 